@@ -0,0 +1,129 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io"
+
+	"github.com/gookit/color"
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"github.com/sitewhere/swctl/pkg/action"
+	"github.com/sitewhere/swctl/pkg/apply"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	helmAction "helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/output"
+)
+
+var applyHelp = `
+Use this command to create or update SiteWhere instances and tenants from a
+YAML file. The file can hold several documents separated by '---', each one a
+SiteWhereInstance, a SiteWhereTenant or a swctl shorthand:
+
+  apiVersion: swctl.sitewhere.io/v1
+  kind: Instance
+  name: sitewhere
+  profile: minimal
+  tag: 3.0.5
+  ---
+  apiVersion: swctl.sitewhere.io/v1
+  kind: Tenant
+  name: acme
+  instance: sitewhere
+  authenticationToken: acme
+
+Objects that exist are patched with the labels, annotations and spec fields
+of the file. Applying the same file again leaves them unchanged.
+
+To apply a file use:
+
+  swctl apply -f sitewhere.yaml
+
+To read the documents from stdin use:
+
+  cat sitewhere.yaml | swctl apply -f -
+`
+
+func newApplyCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewApply(cfg, settings)
+	var outFmt output.Format
+
+	cmd := &cobra.Command{
+		Use:               "apply -f FILENAME",
+		Short:             "Create or update SiteWhere instances and tenants from a file",
+		Long:              applyHelp,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client.In = cmd.InOrStdin()
+			results, err := client.Run()
+			if err != nil {
+				if results != nil && len(results.Objects) > 0 && outFmt == output.Table {
+					outFmt.Write(out, newApplyWriter(results))
+				}
+				return err
+			}
+			return outFmt.Write(out, newApplyWriter(results))
+		},
+	}
+
+	f := cmd.Flags()
+
+	f.StringVarP(&client.Filename, "filename", "f", client.Filename, "YAML file with the objects to apply, - to read from stdin.")
+	cmd.MarkFlagRequired("filename")
+
+	bindOutputFlag(cmd, &outFmt)
+
+	return cmd
+}
+
+type applyWriter struct {
+	Results *apply.SiteWhereApply `json:"results"`
+}
+
+func newApplyWriter(results *apply.SiteWhereApply) *applyWriter {
+	return &applyWriter{Results: results}
+}
+
+func (i *applyWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	table.AddRow("KIND", "NAME", "NAMESPACE", "RESULT")
+	for _, obj := range i.Results.Objects {
+		table.AddRow(obj.Kind, obj.Name, valueOrNone(obj.Namespace), renderApplyResult(obj.Result))
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (i *applyWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, i)
+}
+
+func (i *applyWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, i)
+}
+
+func renderApplyResult(result apply.Result) string {
+	switch result {
+	case apply.Created:
+		return color.Info.Render(string(result))
+	case apply.Configured:
+		return color.Warn.Render(string(result))
+	}
+	return string(result)
+}
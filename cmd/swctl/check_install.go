@@ -19,10 +19,14 @@ package main
 import (
 	"io"
 
+	"github.com/gookit/color"
+	"github.com/gosuri/uitable"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/sitewhere/swctl/pkg/action"
 	"github.com/sitewhere/swctl/pkg/install"
+	"github.com/sitewhere/swctl/pkg/status"
 
 	"helm.sh/helm/v3/cmd/helm/require"
 	helmAction "helm.sh/helm/v3/pkg/action"
@@ -31,25 +35,39 @@ import (
 
 var checkInstallHelp = `
 Use this command to check the install of SiteWhere 3.0 on a Kubernetes Cluster.
+This command will check:
+ - SiteWhere Custom Resources Definitions are installed and established.
+ - SiteWhere Operator is ready.
+ - SiteWhere Infrastructure workloads are ready.
+ - SiteWhere Helm release is deployed.
+
+The command exits with a non-zero status when any component is missing
+or not ready.
 `
 
 func newCheckInstallCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Command {
-	client := action.NewCheckInstall(cfg)
+	client := action.NewCheckInstall(cfg, settings)
 	var outFmt output.Format
 
 	cmd := &cobra.Command{
-		Use:     "check-install",
-		Short:   "Check Install SiteWhere CRD and Operators",
-		Aliases: []string{"check"},
-		Long:    checkInstallHelp,
-		Args:    require.NoArgs,
-		//ValidArgsFunction: noCompletions,
+		Use:               "check-install",
+		Short:             "Check Install SiteWhere CRD and Operators",
+		Aliases:           []string{"check"},
+		Long:              checkInstallHelp,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noCompletions,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			results, err := client.Run()
 			if err != nil {
 				return err
 			}
-			return outFmt.Write(out, newCheckInstallWriter(results))
+			if err = outFmt.Write(out, newCheckInstallWriter(results)); err != nil {
+				return err
+			}
+			if !results.Complete() {
+				return errors.New("SiteWhere installation is not complete")
+			}
+			return nil
 		},
 	}
 
@@ -59,15 +77,25 @@ func newCheckInstallCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Com
 }
 
 type checkInstallWriter struct {
+	Results *install.SiteWhereInstall `json:"results"`
 }
 
-func newCheckInstallWriter(install *install.SiteWhereInstall) *checkInstallWriter {
-	return &checkInstallWriter{}
+func newCheckInstallWriter(results *install.SiteWhereInstall) *checkInstallWriter {
+	return &checkInstallWriter{Results: results}
 }
 
 func (i *checkInstallWriter) WriteTable(out io.Writer) error {
-	//return output.EncodeTable(out, table)
-	return nil
+	table := uitable.New()
+	table.AddRow("COMPONENT", "KIND", "STATUS", "DETAIL")
+	for _, component := range i.Results.Components {
+		table.AddRow(component.Name, component.Kind, renderInstallStatus(component.Status), component.Detail)
+	}
+	if i.Results.Complete() {
+		table.AddRow(color.Style{color.FgGreen, color.OpBold}.Render("SiteWhere 3.0 Installed"))
+	} else {
+		table.AddRow(color.Style{color.FgRed, color.OpBold}.Render("SiteWhere 3.0 Installation not complete"))
+	}
+	return output.EncodeTable(out, table)
 }
 
 func (i *checkInstallWriter) WriteJSON(out io.Writer) error {
@@ -77,3 +105,20 @@ func (i *checkInstallWriter) WriteJSON(out io.Writer) error {
 func (i *checkInstallWriter) WriteYAML(out io.Writer) error {
 	return output.EncodeYAML(out, i)
 }
+
+func renderInstallStatus(s status.Status) string {
+	switch s {
+	case status.Installed:
+		return color.Info.Render("Installed")
+	case status.NotReady:
+		return color.Warn.Render("Not Ready")
+	case status.Skipped:
+		return color.Warn.Render("Skipped")
+	case status.Uninstalled:
+		return color.Error.Render("Missing")
+	case status.RolledBack:
+		return color.Warn.Render("Rolled Back")
+	default:
+		return color.Warn.Render("Unknown")
+	}
+}
@@ -0,0 +1,292 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"github.com/sitewhere/swctl/pkg/action"
+	"github.com/sitewhere/swctl/pkg/config"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	helmAction "helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/output"
+)
+
+var configHelp = `
+Manage swctl configuration file, ~/.swctl/config.yaml by default.
+`
+
+var setInfrastructureHelp = `
+Use this command to store an external infrastructure service in swctl
+configuration file. The instances created afterwards use this service
+instead of the one installed by swctl. The services are kafka, postgresql,
+influxdb, redis and keycloak. For example:
+
+  swctl config set-infrastructure kafka --host kafka.example.com --port 9092
+  swctl config set-infrastructure postgresql --host pg.example.com --port 5432 --username sitewhere --password secret
+
+The credentials are stored in plain text in the configuration file, and in
+the environment of the microservices in the SiteWhere Instance and
+Microservice Custom Resources. Anyone who can read these resources can read
+the credentials, use accounts dedicated to SiteWhere.
+`
+
+var sizesHelp = `
+Show the sizing presets used by swctl install --size. The built-in presets
+are small, medium and large. Other presets can be defined as YAML files in
+~/.swctl/sizes, the name of the file is the name of the preset. For example,
+~/.swctl/sizes/edge.yaml:
+
+  description: Edge gateway
+  kafka:
+    replicas: 1
+    requests:
+      cpu: 250m
+      memory: 512Mi
+    storage: 5Gi
+  zookeeper:
+    replicas: 1
+
+The components are kafka, zookeeper, postgresql, influxdb, redis and keycloak.
+`
+
+func newConfigCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "config",
+		Short:             "manage swctl configuration",
+		Long:              configHelp,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noCompletions,
+	}
+
+	cmd.AddCommand(newConfigInfrastructureCmd(out))
+	cmd.AddCommand(newSetInfrastructureCmd(out))
+	cmd.AddCommand(newUnsetInfrastructureCmd(out))
+	cmd.AddCommand(newConfigSizesCmd(out))
+
+	return cmd
+}
+
+func newConfigInfrastructureCmd(out io.Writer) *cobra.Command {
+	var outFmt output.Format
+
+	cmd := &cobra.Command{
+		Use:               "infrastructure",
+		Short:             "show the external infrastructure",
+		Args:              require.NoArgs,
+		ValidArgsFunction: noCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return outFmt.Write(out, newInfrastructureWriter(&settings.Infrastructure))
+		},
+	}
+
+	bindOutputFlag(cmd, &outFmt)
+
+	return cmd
+}
+
+func newConfigSizesCmd(out io.Writer) *cobra.Command {
+	var outFmt output.Format
+
+	cmd := &cobra.Command{
+		Use:               "sizes",
+		Short:             "show the infrastructure sizing presets",
+		Long:              sizesHelp,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			presets, err := config.ListSizingPresets(config.GetSizingPresetsPath())
+			if err != nil {
+				return err
+			}
+			return outFmt.Write(out, newSizesWriter(presets))
+		},
+	}
+
+	bindOutputFlag(cmd, &outFmt)
+
+	return cmd
+}
+
+func newSetInfrastructureCmd(out io.Writer) *cobra.Command {
+	client := action.NewSetInfrastructure(settings)
+	var outFmt output.Format
+
+	cmd := &cobra.Command{
+		Use:               "set-infrastructure SERVICE",
+		Short:             "use an external infrastructure service",
+		Long:              setInfrastructureHelp,
+		Args:              require.ExactArgs(1),
+		ValidArgsFunction: compExternalServices,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client.Service = args[0]
+			infra, err := client.Run()
+			if err != nil {
+				return err
+			}
+			return outFmt.Write(out, newInfrastructureWriter(infra))
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&client.Endpoint.Host, "host", "", "Host name of the service.")
+	f.Int32Var(&client.Endpoint.Port, "port", 0, "Port of the service, the default port of the service if not set.")
+	f.StringVar(&client.Endpoint.Username, "username", "", "Username used to connect to the service.")
+	f.StringVar(&client.Endpoint.Password, "password", "", "Password used to connect to the service.")
+	f.StringVar(&client.Endpoint.Database, "database", "", "Database name.")
+	bindOutputFlag(cmd, &outFmt)
+
+	return cmd
+}
+
+func newUnsetInfrastructureCmd(out io.Writer) *cobra.Command {
+	client := action.NewSetInfrastructure(settings)
+	var outFmt output.Format
+
+	cmd := &cobra.Command{
+		Use:               "unset-infrastructure SERVICE",
+		Short:             "use the infrastructure service installed by swctl",
+		Args:              require.ExactArgs(1),
+		ValidArgsFunction: compExternalServices,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client.Service = args[0]
+			client.Unset = true
+			infra, err := client.Run()
+			if err != nil {
+				return err
+			}
+			return outFmt.Write(out, newInfrastructureWriter(infra))
+		},
+	}
+
+	bindOutputFlag(cmd, &outFmt)
+
+	return cmd
+}
+
+func compExternalServices(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return action.ExternalServices(), cobra.ShellCompDirectiveNoFileComp
+}
+
+type infrastructureWriter struct {
+	Infrastructure *config.ExternalInfrastructure `json:"infrastructure"`
+}
+
+func newInfrastructureWriter(infra *config.ExternalInfrastructure) *infrastructureWriter {
+	return &infrastructureWriter{Infrastructure: infra}
+}
+
+func (i *infrastructureWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	table.AddRow("SERVICE", "HOST", "PORT", "USERNAME", "PASSWORD", "DATABASE")
+	addEndpointRow(table, "kafka", i.Infrastructure.KafkaEndpoint())
+	addEndpointRow(table, "postgresql", i.Infrastructure.PostgreSQLEndpoint())
+	addEndpointRow(table, "influxdb", i.Infrastructure.InfluxDBEndpoint())
+	addEndpointRow(table, "redis", i.Infrastructure.RedisEndpoint())
+	addEndpointRow(table, "keycloak", i.Infrastructure.Keycloak)
+	return output.EncodeTable(out, table)
+}
+
+func (i *infrastructureWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, i.masked())
+}
+
+func (i *infrastructureWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, i.masked())
+}
+
+// masked returns the writer with the passwords hidden
+func (i *infrastructureWriter) masked() *infrastructureWriter {
+	infra := *i.Infrastructure
+	for _, endpoint := range []*config.ServiceEndpoint{&infra.Kafka, &infra.PostgreSQL, &infra.InfluxDB, &infra.Redis, &infra.Keycloak} {
+		endpoint.Password = maskPassword(endpoint.Password)
+	}
+	return &infrastructureWriter{Infrastructure: &infra}
+}
+
+func addEndpointRow(table *uitable.Table, service string, endpoint config.ServiceEndpoint) {
+	if endpoint.Host == "" {
+		table.AddRow(service, "(installed by swctl)", "", "", "", "")
+		return
+	}
+	var port string
+	if endpoint.Port != 0 {
+		port = fmt.Sprintf("%d", endpoint.Port)
+	}
+	table.AddRow(service, endpoint.Host, port, endpoint.Username, maskPassword(endpoint.Password), endpoint.Database)
+}
+
+func maskPassword(password string) string {
+	if password == "" {
+		return ""
+	}
+	return "********"
+}
+
+type sizesWriter struct {
+	Sizes []config.SizingPreset `json:"sizes"`
+}
+
+func newSizesWriter(presets []config.SizingPreset) *sizesWriter {
+	return &sizesWriter{Sizes: presets}
+}
+
+func (i *sizesWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	table.AddRow("SIZE", "SOURCE", "KAFKA", "ZOOKEEPER", "POSTGRESQL", "INFLUXDB", "REDIS", "KEYCLOAK", "DESCRIPTION")
+	for _, preset := range i.Sizes {
+		source := "user"
+		if preset.BuiltIn {
+			source = "built-in"
+		}
+		table.AddRow(preset.Name, source, renderComponentSize(preset.Kafka), renderComponentSize(preset.Zookeeper),
+			renderComponentSize(preset.PostgreSQL), renderComponentSize(preset.InfluxDB),
+			renderComponentSize(preset.Redis), renderComponentSize(preset.Keycloak), preset.Description)
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (i *sizesWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, i)
+}
+
+func (i *sizesWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, i)
+}
+
+// renderComponentSize renders the replicas and storage of a component
+func renderComponentSize(size config.ComponentSize) string {
+	if size.Replicas == 0 && size.Storage == "" {
+		return "-"
+	}
+	result := "-"
+	if size.Replicas != 0 {
+		result = fmt.Sprintf("%dx", size.Replicas)
+	}
+	if size.Storage != "" {
+		result = fmt.Sprintf("%s %s", result, size.Storage)
+	}
+	return result
+}
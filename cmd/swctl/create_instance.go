@@ -17,6 +17,7 @@
 package main
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/gosuri/uitable"
@@ -25,6 +26,8 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
+	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
+
 	"github.com/sitewhere/swctl/pkg/action"
 	"github.com/sitewhere/swctl/pkg/instance"
 
@@ -42,10 +45,26 @@ For example, to create an instance with name "sitewhere" use:
 To create an instance with the minimal profile use:
 
 	swctl create instance sitewhere -m
+
+To create an instance with a profile of ~/.swctl/profiles use:
+
+  swctl create instance sitewhere --profile edge
+
+To create an instance with the debug mode of the device-management and
+event-management microservices use:
+
+  swctl create instance sitewhere --debug-ms device-management,event-management
+
+To wait for the instance to bootstrap use:
+
+  swctl create instance sitewhere --wait --timeout 15m
+
+The --namespace flag is deprecated and ignored, the operator creates the
+namespace of the instance with the instance name.
 `
 
 func newCreateInstanceCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Command {
-	client := action.NewCreateInstance(cfg)
+	client := action.NewCreateInstance(cfg, settings)
 	var outFmt output.Format
 
 	cmd := &cobra.Command{
@@ -54,12 +73,18 @@ func newCreateInstanceCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.C
 		Long:              createInstanceDesc,
 		Args:              require.ExactArgs(1),
 		ValidArgsFunction: noCompletions,
-		RunE: func(_ *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			instanceName, err := client.ExtractInstanceName(args)
 			if err != nil {
 				return err
 			}
 			client.InstanceName = instanceName
+			client.Out = cmd.ErrOrStderr()
+			if outFmt == output.Table {
+				client.Progress = newInstallProgress(out)
+				client.BootstrapProgress = newBootstrapProgress(out)
+				client.Out = out
+			}
 			results, err := client.Run()
 			if err != nil {
 				return err
@@ -75,14 +100,29 @@ func newCreateInstanceCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.C
 }
 
 func addCreateInstanceFlags(cmd *cobra.Command, f *pflag.FlagSet, client *action.CreateInstance) {
-	f.StringVarP(&client.Namespace, "namespace", "n", client.Namespace, "Namespace of the instance.")
+	f.StringVarP(&client.Namespace, "namespace", "n", client.Namespace, "Deprecated and ignored, the operator creates the namespace of the instance with the instance name.")
 	f.BoolVarP(&client.Minimal, "minimal", "m", client.Minimal, "Minimal installation.")
+	f.StringVar(&client.Profile, "profile", client.Profile, "Profile of the instance (default, minimal or a profile of ~/.swctl/profiles).")
 	f.StringVarP(&client.Tag, "tag", "t", client.Tag, "Docker image tag.")
 	f.StringVar(&client.Registry, "registry", client.Registry, "Docker image registry.")
-	f.BoolVarP(&client.Debug, "debug", "d", client.Debug, "Debug mode.")
+	f.BoolVarP(&client.Debug, "debug", "d", client.Debug, "Debug mode of every microservice, with JDWP and JMX ports.")
+	f.StringSliceVar(&client.DebugMicroservices, "debug-ms", client.DebugMicroservices, "Functional areas of the microservices in debug mode.")
+	f.BoolVar(&client.SkipPreflight, "skip-preflight", client.SkipPreflight, "Skip the pre-flight checks.")
 	f.Int32VarP(&client.Replicas, "replicas", "r", client.Replicas, "Number of replicas")
 	f.StringVarP(&client.ConfigurationTemplate, "config-template", "c", client.ConfigurationTemplate, "Configuration template.")
 	f.StringVarP(&client.DatasetTemplate, "dateset-template", "x", client.DatasetTemplate, "Dataset template.")
+	f.BoolVarP(&client.Wait, "wait", "w", client.Wait, "Wait for tenant management, user management and the microservices to bootstrap.")
+	f.DurationVar(&client.Timeout, "timeout", client.Timeout, "Time to wait for the instance to bootstrap.")
+}
+
+// newBootstrapProgress prints a line each time the bootstrap state of a component changes
+func newBootstrapProgress(out io.Writer) action.BootstrapProgressFunc {
+	return func(kind string, name string, state sitewhereiov1alpha4.BootstrapState) {
+		if state == "" {
+			state = "Unknown"
+		}
+		fmt.Fprintf(out, "%s %s %s\n", renderState(state), kind, name)
+	}
 }
 
 type createInstancePrinter struct {
@@ -103,7 +143,11 @@ func (s createInstancePrinter) WriteYAML(out io.Writer) error {
 
 func (s createInstancePrinter) WriteTable(out io.Writer) error {
 	table := uitable.New()
-	table.AddRow("INSTANCE", "STATUS")
-	table.AddRow(s.instance.InstanceName, color.Info.Render("Created"))
+	table.AddRow("INSTANCE", "NAMESPACE", "STATUS")
+	var instanceStatus = color.Info.Render("Created")
+	if s.instance.Bootstrapped {
+		instanceStatus = renderState(sitewhereiov1alpha4.Bootstrapped)
+	}
+	table.AddRow(s.instance.InstanceName, s.instance.Namespace, instanceStatus)
 	return output.EncodeTable(out, table)
 }
@@ -24,6 +24,8 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
+	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
+
 	"github.com/sitewhere/swctl/pkg/action"
 	"github.com/sitewhere/swctl/pkg/tenant"
 
@@ -38,6 +40,10 @@ For example, to create a tenant with name "sitewhereTenant" use:
  
 swctl create tenant sitewhereTenant
 
+To wait for the tenant engines of the tenant to bootstrap use:
+
+swctl create tenant sitewhereTenant --instance sitewhere --wait
+
 `
 
 func newCreateTenantCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Command {
@@ -56,6 +62,10 @@ func newCreateTenantCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Com
 				return err
 			}
 			client.TenantName = tenantNameName
+			if outFmt == output.Table {
+				client.Progress = newInstallProgress(out)
+				client.BootstrapProgress = newBootstrapProgress(out)
+			}
 			results, err := client.Run()
 			if err != nil {
 				return err
@@ -89,7 +99,11 @@ func (s createTenantPrinter) WriteYAML(out io.Writer) error {
 func (s createTenantPrinter) WriteTable(out io.Writer) error {
 	table := uitable.New()
 	table.AddRow("INSTANCE", "TENANT", "STATUS")
-	table.AddRow(s.instance.InstanceName, s.instance.TenantName, color.Info.Render("Installed"))
+	var tenantStatus = color.Info.Render("Installed")
+	if s.instance.Bootstrapped {
+		tenantStatus = renderState(sitewhereiov1alpha4.Bootstrapped)
+	}
+	table.AddRow(s.instance.InstanceName, s.instance.TenantName, tenantStatus)
 	return output.EncodeTable(out, table)
 }
 
@@ -99,6 +113,8 @@ func addCreateTenantFlags(cmd *cobra.Command, f *pflag.FlagSet, client *action.C
 	f.StringVarP(&client.AuthenticationToken, "authenticationToken", "t", client.AuthenticationToken, "AuthenticationToken")
 	f.StringVarP(&client.ConfigurationTemplate, "configurationTemplate", "c", client.ConfigurationTemplate, "Configuration Template")
 	f.StringVarP(&client.DatasetTemplate, "datasetTemplate", "d", client.DatasetTemplate, "Dataset Template")
+	f.BoolVarP(&client.Wait, "wait", "w", client.Wait, "Wait for the tenant engines to bootstrap.")
+	f.DurationVar(&client.Timeout, "timeout", client.Timeout, "Time to wait for the tenant engines to bootstrap.")
 
 	cmd.MarkFlagRequired("instance")
 }
@@ -0,0 +1,88 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sitewhere/swctl/pkg/action"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	helmAction "helm.sh/helm/v3/pkg/action"
+)
+
+var debugHelp = `
+Use this command to forward the JDWP and JMX ports of a SiteWhere Microservice
+to localhost, to attach a Java debugger or a JMX console. The debug mode of the
+microservice must be enabled when creating the instance:
+
+  swctl create instance sitewhere --debug-ms device-management
+
+To forward the ports of the device-management microservice use:
+
+  swctl debug sitewhere device-management
+
+The ports are forwarded until Ctrl-C is pressed.
+`
+
+func newDebugCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewDebug(cfg)
+
+	cmd := &cobra.Command{
+		Use:   "debug [OPTIONS] INSTANCE MS",
+		Short: "forward the JDWP and JMX ports of a SiteWhere Microservice",
+		Long:  debugHelp,
+		Args:  require.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return compListInstances(toComplete, cfg)
+			} else if len(args) == 1 {
+				return compListMicroservices(toComplete, args[0], cfg)
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client.InstanceName = args[0]
+			client.MicroserviceName = args[1]
+			client.Out = out
+			client.StopCh = stopOnInterrupt()
+			return client.Run()
+		},
+	}
+	f := cmd.Flags()
+	f.IntVar(&client.JDWPPort, "jdwp-port", client.JDWPPort, "Local port of JDWP, the port of the microservice if not set.")
+	f.IntVar(&client.JMXPort, "jmx-port", client.JMXPort, "Local port of JMX, the port of the microservice if not set.")
+	return cmd
+}
+
+// stopOnInterrupt returns a channel closed when the process is interrupted
+func stopOnInterrupt() <-chan struct{} {
+	stopCh := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		signal.Stop(signals)
+		close(stopCh)
+	}()
+	return stopCh
+}
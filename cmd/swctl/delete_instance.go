@@ -94,7 +94,10 @@ func (s deleteInstancePrinter) WriteYAML(out io.Writer) error {
 
 func (s deleteInstancePrinter) WriteTable(out io.Writer) error {
 	table := uitable.New()
-	table.AddRow("INSTANCE", "STATUS")
-	table.AddRow(s.instance.InstanceName, color.Info.Render("Deleted"))
+	table.AddRow("INSTANCE", "NAMESPACE", "STATUS")
+	table.AddRow(s.instance.InstanceName, s.instance.Namespace, color.Info.Render("Deleted"))
+	if s.instance.PurgedNamespace != "" {
+		table.AddRow("", s.instance.PurgedNamespace, color.Info.Render("Namespace Deleted"))
+	}
 	return output.EncodeTable(out, table)
 }
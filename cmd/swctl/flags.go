@@ -75,3 +75,66 @@ func (o *outputValue) Set(s string) error {
 	*o = outputValue(outfmt)
 	return nil
 }
+
+// wideFormat is the table format with additional columns
+const wideFormat = "wide"
+
+// bindWideOutputFlag will add the output flag, accepting also the wide
+// format, to the given command and bind the value to the given format and
+// wide pointers
+func bindWideOutputFlag(cmd *cobra.Command, varRef *output.Format, wide *bool) {
+	formats := append(output.Formats(), wideFormat)
+	cmd.Flags().VarP(newWideOutputValue(output.Table, varRef, wide), outputFlag, "o",
+		fmt.Sprintf("prints the output in the specified format. Allowed values: %s", strings.Join(formats, ", ")))
+
+	err := cmd.RegisterFlagCompletionFunc(outputFlag, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var formatNames []string
+		for _, format := range formats {
+			if strings.HasPrefix(format, toComplete) {
+				formatNames = append(formatNames, format)
+			}
+		}
+		return formatNames, cobra.ShellCompDirectiveDefault
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+type wideOutputValue struct {
+	format *output.Format
+	wide   *bool
+}
+
+func newWideOutputValue(defaultValue output.Format, p *output.Format, wide *bool) *wideOutputValue {
+	*p = defaultValue
+	*wide = false
+	return &wideOutputValue{format: p, wide: wide}
+}
+
+func (o *wideOutputValue) String() string {
+	if *o.wide {
+		return wideFormat
+	}
+	return string(*o.format)
+}
+
+func (o *wideOutputValue) Type() string {
+	return "format"
+}
+
+func (o *wideOutputValue) Set(s string) error {
+	if s == wideFormat {
+		*o.format = output.Table
+		*o.wide = true
+		return nil
+	}
+	outfmt, err := output.ParseFormat(s)
+	if err != nil {
+		return err
+	}
+	*o.format = outfmt
+	*o.wide = false
+	return nil
+}
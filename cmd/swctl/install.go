@@ -17,14 +17,18 @@
 package main
 
 import (
+	"fmt"
 	"io"
+	"strings"
 
 	"github.com/gookit/color"
 	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/sitewhere/swctl/pkg/action"
 	"github.com/sitewhere/swctl/pkg/install"
+	"github.com/sitewhere/swctl/pkg/status"
 
 	"helm.sh/helm/v3/cmd/helm/require"
 	helmAction "helm.sh/helm/v3/pkg/action"
@@ -39,6 +43,36 @@ This command will install:
  - SiteWhere Templates.
  - SiteWhere Operator.
  - SiteWhere Infrastructure.
+
+The values of the infrastructure components can be tuned with values files
+(-f/--values) and --set, --set-string and --set-file overrides. These are
+merged on top of the values derived from the other flags. For example:
+
+  swctl install -f my-values.yaml --set keycloak.replicas=2
+
+Use --with and --without to select the infrastructure components to install,
+for example to use a managed PostgreSQL and Kafka:
+
+  swctl install --without postgresql,strimzi
+
+Use --size to apply a sizing preset (small, medium, large, or a preset
+defined in ~/.swctl/sizes) to the replicas, resources and PVC sizes of
+Kafka, Zookeeper, PostgreSQL, InfluxDB, Redis and Keycloak. The other flags
+and the values overrides take precedence over the preset:
+
+  swctl install --size medium --kafka-pvc-size 100Gi
+
+On disconnected networks, install from a local chart archive or directory
+whose dependencies are vendored in its charts/ folder:
+
+  swctl install --chart-path ./sitewhere-infrastructure-0.1.13.tgz
+
+With --atomic, a failed install is rolled back: the release is uninstalled
+and the Custom Resource Definitions and the namespace created by the install
+are deleted. The rolled back resources are reported.
+
+With --wait, the readiness of the Deployments, StatefulSets and Strimzi
+Kafka clusters in the SiteWhere System Namespace is reported while waiting.
 `
 
 func newInstallCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Command {
@@ -52,8 +86,29 @@ func newInstallCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Command
 		Args:              require.NoArgs,
 		ValidArgsFunction: noCompletions,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if client.DryRun {
+				results, err := client.Template()
+				if err != nil {
+					return err
+				}
+				return outFmt.Write(out, newTemplateWriter(results))
+			}
+			client.Out = cmd.ErrOrStderr()
+			if outFmt == output.Table {
+				client.Progress = newInstallProgress(out)
+				client.Out = out
+			}
 			results, err := client.Run()
 			if err != nil {
+				if results != nil {
+					var writer output.Writer = newInstallWriter(client.SkipCRD, client.SkipTemplate, client.SkipOperator, client.SkipInfrastructure, results)
+					if len(results.RolledBack) > 0 {
+						writer = newInstallRollbackWriter(results)
+					}
+					if writeErr := outFmt.Write(out, writer); writeErr != nil {
+						return writeErr
+					}
+				}
 				return err
 			}
 			return outFmt.Write(out, newInstallWriter(client.SkipCRD, client.SkipTemplate, client.SkipOperator, client.SkipInfrastructure, results))
@@ -63,21 +118,44 @@ func newInstallCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Command
 	f := cmd.Flags()
 
 	f.BoolVarP(&client.WaitReady, "wait", "w", false, "Wait for components to be ready before return control.")
+	f.BoolVar(&client.Atomic, "atomic", false, "If the install fails, uninstall the release and delete the CRDs and namespace it created. Implies --wait.")
+	f.DurationVar(&client.Timeout, "timeout", client.Timeout, "Time to wait for the install to complete.")
 	f.BoolVar(&client.SkipCRD, "skip-crd", false, "Skip Custom Resource Definition installation.")
-	f.BoolVar(&client.SkipTemplate, "skip-templates", false, "Skip Templates installation.")
-	f.BoolVar(&client.SkipOperator, "skip-operator", false, "Skip Operator installation.")
-	f.BoolVar(&client.SkipInfrastructure, "skip-infra", false, "Skip Infrastructure installation.")
-	f.BoolVarP(&client.Minimal, "minimal", "m", client.Minimal, "Install minimal infrastructure.")
-	f.StringVarP(&client.StorageClass, "storage-class", "s", "", "Storage Class of infrastructure components.")
-	f.StringVar(&client.KafkaPVCStorageSize, "kafka-pvc-size", "", "Kafka PVC Storage Size.")
-	f.StringVar(&client.InfluxDBPVCStorageSize, "influxdb-pvc-size", "", "InfluxDB PVC Storage Size.")
-	f.StringVar(&client.HelmChartVersion, "chart-version", client.HelmChartVersion, "SiteWhere Infrastructure Helm Chart version to use.")
+	f.BoolVar(&client.SkipPreflight, "skip-preflight", false, "Skip the pre-flight checks.")
+	f.BoolVar(&client.DryRun, "dry-run", false, "Render the manifests without contacting the cluster.")
+	f.StringVar(&client.OutputDir, "output-dir", "", "Write the rendered manifests to this directory instead of stdout.")
+	addInfrastructureValuesFlags(f, &client.InfrastructureValues)
+	addChartSourceFlags(f, &client.ChartSource)
 
 	bindOutputFlag(cmd, &outFmt)
 
 	return cmd
 }
 
+func addChartSourceFlags(f *pflag.FlagSet, c *action.ChartSource) {
+	f.StringVar(&c.ChartPath, "chart-path", "", "Path of a local SiteWhere Infrastructure chart archive (.tgz) or directory. Skips the chart repository.")
+	f.BoolVar(&c.SkipRepoUpdate, "skip-repo-update", false, "Skip adding and updating the SiteWhere chart repository.")
+}
+
+func addInfrastructureValuesFlags(f *pflag.FlagSet, v *action.InfrastructureValues) {
+	f.BoolVar(&v.SkipTemplate, "skip-templates", false, "Skip Templates installation.")
+	f.BoolVar(&v.SkipOperator, "skip-operator", false, "Skip Operator installation.")
+	f.BoolVar(&v.SkipInfrastructure, "skip-infra", false, "Skip Infrastructure installation.")
+	f.StringSliceVar(&v.With, "with", []string{}, fmt.Sprintf("Infrastructure components to install, all if not set (%s).", strings.Join(install.ComponentNames(), ", ")))
+	f.StringSliceVar(&v.Without, "without", []string{}, "Infrastructure components not to install.")
+	f.BoolVarP(&v.Minimal, "minimal", "m", v.Minimal, "Install minimal infrastructure.")
+	f.StringVar(&v.Profile, "profile", v.Profile, "Profile whose infrastructure settings are used (default, minimal or a profile of ~/.swctl/profiles).")
+	f.StringVar(&v.Size, "size", v.Size, "Sizing preset of the infrastructure (small, medium, large or a preset of ~/.swctl/sizes).")
+	f.StringVarP(&v.StorageClass, "storage-class", "s", "", "Storage Class of infrastructure components.")
+	f.StringVar(&v.KafkaPVCStorageSize, "kafka-pvc-size", "", "Kafka PVC Storage Size.")
+	f.StringVar(&v.InfluxDBPVCStorageSize, "influxdb-pvc-size", "", "InfluxDB PVC Storage Size.")
+	f.StringVar(&v.HelmChartVersion, "chart-version", v.HelmChartVersion, "SiteWhere Infrastructure Helm Chart version to use.")
+	f.StringSliceVarP(&v.ValueOptions.ValueFiles, "values", "f", []string{}, "Specify values in a YAML file or a URL (can specify multiple).")
+	f.StringArrayVar(&v.ValueOptions.Values, "set", []string{}, "Set values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2).")
+	f.StringArrayVar(&v.ValueOptions.StringValues, "set-string", []string{}, "Set STRING values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2).")
+	f.StringArrayVar(&v.ValueOptions.FileValues, "set-file", []string{}, "Set values from respective files specified via the command line (can specify multiple or separate values with commas: key1=path1,key2=path2).")
+}
+
 type installWriter struct {
 	SkipCRD            bool
 	SkipTemplate       bool
@@ -98,28 +176,27 @@ func newInstallWriter(skipCRD bool, skipTemplate bool, skipOperator bool, skipIn
 
 func (i *installWriter) WriteTable(out io.Writer) error {
 	table := uitable.New()
-	table.AddRow("COMPONENT", "STATUS")
+	table.AddRow("COMPONENT", "KIND", "STATUS", "DETAIL")
 	if i.SkipCRD {
-		table.AddRow("Custom Resource Definitions", color.Warn.Render("Skipped"))
-	} else {
-		table.AddRow("Custom Resource Definitions", color.Info.Render("Installed"))
+		table.AddRow("Custom Resource Definitions", "", color.Warn.Render("Skipped"), "")
 	}
 	if i.SkipTemplate {
-		table.AddRow("Templates", color.Warn.Render("Skipped"))
-	} else {
-		table.AddRow("Templates", color.Info.Render("Installed"))
+		table.AddRow("Templates", "", color.Warn.Render("Skipped"), "")
 	}
 	if i.SkipOperator {
-		table.AddRow("Operator", color.Warn.Render("Skipped"))
-	} else {
-		table.AddRow("Operator", color.Info.Render("Installed"))
+		table.AddRow("Operator", "", color.Warn.Render("Skipped"), "")
+	}
+	for _, component := range i.Results.Infrastructure {
+		table.AddRow(component.Name, component.Kind, renderInstallStatus(component.Status), component.Detail)
+	}
+	for _, component := range i.Results.Components {
+		table.AddRow(component.Name, component.Kind, renderInstallStatus(component.Status), component.Detail)
 	}
-	if i.SkipInfrastructure {
-		table.AddRow("Infrastructure", color.Warn.Render("Skipped"))
+	if i.Results.Complete() {
+		table.AddRow(color.Style{color.FgGreen, color.OpBold}.Render("SiteWhere 3.0 Installed"))
 	} else {
-		table.AddRow("Infrastructure", color.Info.Render("Installed"))
+		table.AddRow(color.Style{color.FgYellow, color.OpBold}.Render("SiteWhere 3.0 Installed, some components are not ready yet"))
 	}
-	table.AddRow(color.Style{color.FgGreen, color.OpBold}.Render("SiteWhere 3.0 Installed"))
 	return output.EncodeTable(out, table)
 }
 
@@ -130,3 +207,43 @@ func (i *installWriter) WriteJSON(out io.Writer) error {
 func (i *installWriter) WriteYAML(out io.Writer) error {
 	return output.EncodeYAML(out, i)
 }
+
+// newInstallProgress prints a line each time the status of a component changes
+func newInstallProgress(out io.Writer) action.ProgressFunc {
+	return func(component status.SiteWhereStatus) {
+		fmt.Fprintf(out, "%s %s %s %s\n", renderInstallStatus(component.Status), component.Kind, component.Name, component.Detail)
+	}
+}
+
+type installRollbackWriter struct {
+	Results *install.SiteWhereInstall `json:"results"`
+}
+
+func newInstallRollbackWriter(results *install.SiteWhereInstall) *installRollbackWriter {
+	return &installRollbackWriter{Results: results}
+}
+
+func (i *installRollbackWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	table.AddRow("ROLLED BACK", "KIND", "STATUS", "DETAIL")
+	for _, resource := range i.Results.RolledBack {
+		table.AddRow(resource.Name, resource.Kind, renderRollbackStatus(resource.Status), resource.Detail)
+	}
+	table.AddRow(color.Style{color.FgRed, color.OpBold}.Render("SiteWhere 3.0 Install rolled back"))
+	return output.EncodeTable(out, table)
+}
+
+func (i *installRollbackWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, i)
+}
+
+func (i *installRollbackWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, i)
+}
+
+func renderRollbackStatus(s status.Status) string {
+	if s == status.Uninstalled {
+		return color.Info.Render("Removed")
+	}
+	return color.Error.Render("Failed")
+}
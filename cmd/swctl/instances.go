@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/gookit/color"
 	"github.com/gosuri/uitable"
@@ -33,6 +34,8 @@ import (
 	"helm.sh/helm/v3/cmd/helm/require"
 	helmAction "helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/cli/output"
+
+	"k8s.io/apimachinery/pkg/util/duration"
 )
 
 var instancesHelp = `
@@ -42,6 +45,7 @@ Use this command to list SiteWhere Intances.
 func newInstancesCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewInstances(cfg)
 	var outFmt output.Format
+	var wide bool
 
 	cmd := &cobra.Command{
 		Use:   "instances [NAME]",
@@ -62,10 +66,12 @@ func newInstancesCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Comman
 			if err != nil {
 				return err
 			}
-			return outFmt.Write(out, newInstancesWriter(results))
+			writer := newInstancesWriter(results)
+			writer.wide = wide
+			return outFmt.Write(out, writer)
 		},
 	}
-	bindOutputFlag(cmd, &outFmt)
+	bindWideOutputFlag(cmd, &outFmt, &wide)
 	return cmd
 }
 
@@ -73,14 +79,29 @@ type instancesWriter struct {
 	// Instances found
 	Instances []sitewhereiov1alpha4.SiteWhereInstance
 
+	// Namespaces of the instances, by instance name
+	Namespaces map[string]string
+
 	//Microservices found
 	Microservices []sitewhereiov1alpha4.SiteWhereMicroservice
+
+	// Health of the microservices found
+	Health []instance.MicroserviceHealth `json:"health,omitempty"`
+
+	// Summaries of the instances, by instance name
+	Summaries map[string]instance.InstanceSummary `json:"summaries,omitempty"`
+
+	// wide prints additional columns
+	wide bool
 }
 
 func newInstancesWriter(result *instance.ListSiteWhereInstance) *instancesWriter {
 	return &instancesWriter{
 		Instances:     result.Instances,
+		Namespaces:    result.Namespaces,
 		Microservices: result.Microservices,
+		Health:        result.Health,
+		Summaries:     result.Summaries,
 	}
 }
 
@@ -90,12 +111,15 @@ func (i *instancesWriter) WriteTable(out io.Writer) error {
 	for _, item := range i.Instances {
 		tmState := renderState(item.Status.TenantManagementBootstrapState)
 		umStatus := renderState(item.Status.UserManagementBootstrapState)
-		table.AddRow(item.Name, item.Name, item.Spec.ConfigurationTemplate, item.Spec.DatasetTemplate, tmState, umStatus)
+		table.AddRow(item.Name, i.Namespaces[item.Name], item.Spec.ConfigurationTemplate, item.Spec.DatasetTemplate, tmState, umStatus)
 	}
 
 	table.AddRow("", "", "", "", "")
 	output.EncodeTable(out, table)
 
+	if len(i.Summaries) > 0 {
+		i.WriteSummaryInfo(out)
+	}
 	if len(i.Instances) == 1 && len(i.Microservices) > 0 {
 		i.WriteMicroserviceInfo(out)
 		i.WriteInstanceDetailInfo(out, i.Instances[0])
@@ -103,13 +127,51 @@ func (i *instancesWriter) WriteTable(out io.Writer) error {
 	return nil
 }
 
+func (i *instancesWriter) WriteSummaryInfo(out io.Writer) {
+	summaryTable := uitable.New()
+	summaryTable.AddRow("INSTANCE", "MICROSERVICES", "REPLICAS", "RESTARTS", "TAGS")
+	for _, item := range i.Instances {
+		summary, ok := i.Summaries[item.Name]
+		if !ok {
+			continue
+		}
+		if summary.Error != "" {
+			summaryTable.AddRow(item.Name, "unknown", "unknown", "unknown", "unknown")
+			continue
+		}
+		summaryTable.AddRow(item.Name,
+			renderReplicas(int32(summary.ReadyMicroservices), int32(summary.Microservices)),
+			renderReplicas(summary.Available, summary.Desired),
+			renderRestarts(summary.Restarts),
+			valueOrNone(strings.Join(summary.Tags, ",")))
+	}
+	summaryTable.AddRow("", "", "", "", "")
+	output.EncodeTable(out, summaryTable)
+}
+
 func (i *instancesWriter) WriteMicroserviceInfo(out io.Writer) {
 	microserviceTable := uitable.New()
-	microserviceTable.AddRow("MICROSERVICE", "NAMESPACE", "DEPLOYMENT")
-	for _, item := range i.Microservices {
-		microserviceTable.AddRow(item.Spec.Name, item.ObjectMeta.Namespace, item.Status.Deployment)
+	if i.wide {
+		microserviceTable.AddRow("MICROSERVICE", "NAMESPACE", "DEPLOYMENT", "DESIRED", "UP-TO-DATE", "READY", "AVAILABLE",
+			"TAG", "RESTARTS", "AGE", "LAST TERMINATION", "IMAGE", "NODES")
+	} else {
+		microserviceTable.AddRow("MICROSERVICE", "DESIRED", "READY", "AVAILABLE", "TAG", "RESTARTS", "AGE", "LAST TERMINATION")
+	}
+	for _, item := range i.Health {
+		tag := valueOrNone(strings.Join(item.Tags, ","))
+		age := renderAge(item)
+		lastTermination := renderTerminationReason(item.LastTerminationReason)
+		if i.wide {
+			microserviceTable.AddRow(item.Name, item.Namespace, valueOrNone(item.Deployment), item.Desired, item.Updated,
+				renderReplicas(item.Ready, item.Desired), renderReplicas(item.Available, item.Desired), tag,
+				renderRestarts(item.Restarts), age, lastTermination,
+				valueOrNone(strings.Join(item.Images, ",")), valueOrNone(strings.Join(item.Nodes, ",")))
+		} else {
+			microserviceTable.AddRow(item.Name, item.Desired, renderReplicas(item.Ready, item.Desired),
+				renderReplicas(item.Available, item.Desired), tag, renderRestarts(item.Restarts), age, lastTermination)
+		}
 	}
-	microserviceTable.AddRow("", "", "")
+	microserviceTable.AddRow("")
 	output.EncodeTable(out, microserviceTable)
 	output.EncodeYAML(out, i.Instances[0].Spec.DockerSpec)
 	output.EncodeYAML(out, i.Instances[0].Spec.Configuration)
@@ -137,11 +199,44 @@ func renderState(state sitewhereiov1alpha4.BootstrapState) string {
 		return color.Info.Render("Bootstrapped")
 	case "NotBootstrapped":
 		return color.Error.Render("Not Bootstrapped")
+	case "Bootstrapping":
+		return color.Warn.Render("Bootstrapping")
+	case "BootstrapFailed":
+		return color.Error.Render("Bootstrap Failed")
 	default:
 		return ""
 	}
 }
 
+func renderReplicas(current int32, desired int32) string {
+	value := fmt.Sprintf("%d/%d", current, desired)
+	if current < desired {
+		return color.Warn.Render(value)
+	}
+	return color.Info.Render(value)
+}
+
+func renderRestarts(restarts int32) string {
+	if restarts > 0 {
+		return color.Warn.Render(restarts)
+	}
+	return fmt.Sprintf("%d", restarts)
+}
+
+func renderAge(health instance.MicroserviceHealth) string {
+	if health.Created == nil || health.Created.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(health.Created.Time))
+}
+
+func renderTerminationReason(reason string) string {
+	if reason == "" {
+		return valueOrNone(reason)
+	}
+	return color.Error.Render(reason)
+}
+
 // Provide dynamic auto-completion for sitewhere instances names
 func compListInstances(toComplete string, cfg *helmAction.Configuration) ([]string, cobra.ShellCompDirective) {
 	cobra.CompDebugln(fmt.Sprintf("compListInstances with toComplete %s", toComplete), settings.Debug)
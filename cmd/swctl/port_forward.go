@@ -0,0 +1,78 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sitewhere/swctl/pkg/action"
+
+	helmAction "helm.sh/helm/v3/pkg/action"
+)
+
+var portForwardHelp = `
+Use this command to forward local ports to the ports of a SiteWhere Microservice.
+The ports are the names or the numbers of the ports of the microservice service,
+[LOCAL:]PORT to choose the local port, or :PORT for a random local port. Without
+ports, every port of the microservice is forwarded to the same local port.
+Without microservice, the ports of instance-management, with the REST and gRPC
+APIs of the instance, are forwarded.
+
+To forward the REST API of the instance sitewhere to localhost:8080 use:
+
+  swctl port-forward sitewhere -p http-rest
+
+To forward the metrics of device-management to a random local port use:
+
+  swctl port-forward sitewhere device-management -p :http-metrics
+
+The ports are forwarded until Ctrl-C is pressed.
+`
+
+func newPortForwardCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewPortForward(cfg)
+
+	cmd := &cobra.Command{
+		Use:   "port-forward [OPTIONS] INSTANCE [MS]",
+		Short: "forward local ports to the ports of a SiteWhere Microservice",
+		Long:  portForwardHelp,
+		Args:  cobra.RangeArgs(1, 2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return compListInstances(toComplete, cfg)
+			} else if len(args) == 1 {
+				return compListMicroservices(toComplete, args[0], cfg)
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client.InstanceName = args[0]
+			if len(args) > 1 {
+				client.MicroserviceName = args[1]
+			}
+			client.Out = out
+			client.StopCh = stopOnInterrupt()
+			return client.Run()
+		},
+	}
+	f := cmd.Flags()
+	f.StringSliceVarP(&client.Ports, "port", "p", client.Ports, "Ports to forward, as [LOCAL:]PORT where PORT is the name or the number of a port of the microservice.")
+	f.StringSliceVar(&client.Addresses, "address", client.Addresses, "Addresses to listen on.")
+	return cmd
+}
@@ -0,0 +1,112 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io"
+
+	"github.com/gookit/color"
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"github.com/sitewhere/swctl/pkg/action"
+	"github.com/sitewhere/swctl/pkg/preflight"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	helmAction "helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/output"
+)
+
+var preflightHelp = `
+Use this command to check that a Kubernetes Cluster is ready for SiteWhere 3.0.
+This command will check:
+ - Kubernetes server version is in the supported range.
+ - The Storage Class given with --storage-class exists, or a default Storage Class is set.
+ - Nodes have enough allocatable CPU and memory for the default or minimal profile.
+ - The current user can create cluster-scoped resources.
+ - Istio control plane is running.
+
+Use --instance to run only the checks used before creating an instance.
+The command exits with a non-zero status when any check fails.
+`
+
+func newPreflightCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewPreflight(cfg)
+	var outFmt output.Format
+
+	cmd := &cobra.Command{
+		Use:               "preflight",
+		Short:             "Run the pre-flight checks for SiteWhere",
+		Long:              preflightHelp,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, err := client.Run()
+			if err != nil {
+				return err
+			}
+			if err = outFmt.Write(out, newPreflightWriter(report)); err != nil {
+				return err
+			}
+			return report.Err()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&client.StorageClass, "storage-class", "s", client.StorageClass, "Storage Class of infrastructure components.")
+	f.BoolVarP(&client.Minimal, "minimal", "m", client.Minimal, "Check the requirements of the minimal profile.")
+	f.BoolVar(&client.Instance, "instance", client.Instance, "Run the checks for instance creation.")
+	bindOutputFlag(cmd, &outFmt)
+
+	return cmd
+}
+
+type preflightWriter struct {
+	Report *preflight.Report `json:"report"`
+}
+
+func newPreflightWriter(report *preflight.Report) *preflightWriter {
+	return &preflightWriter{Report: report}
+}
+
+func (p *preflightWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	table.AddRow("CHECK", "RESULT", "MESSAGE")
+	for _, result := range p.Report.Results {
+		table.AddRow(result.Name, renderPreflightResult(result.Result), result.Message)
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (p *preflightWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, p)
+}
+
+func (p *preflightWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, p)
+}
+
+func renderPreflightResult(r preflight.Result) string {
+	switch r {
+	case preflight.Pass:
+		return color.Info.Render("PASS")
+	case preflight.Warn:
+		return color.Warn.Render("WARN")
+	default:
+		return color.Error.Render("FAIL")
+	}
+}
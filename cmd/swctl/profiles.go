@@ -0,0 +1,137 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io"
+	"strings"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"github.com/sitewhere/swctl/pkg/config"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	helmAction "helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/output"
+)
+
+var profilesHelp = `
+Show the profiles used by swctl install --profile and swctl create instance
+--profile. The built-in profiles are default and minimal. Other profiles are
+configuration templates stored as YAML files in ~/.swctl/profiles, the name
+of the file is the name of the profile. For example, ~/.swctl/profiles/edge.yaml:
+
+  profile:
+    description: Edge gateway
+    base: minimal
+    functionalAreas:
+    - instance-management
+    - device-management
+    - event-sources
+    - inbound-processing
+    - event-management
+    infrastructure:
+      minimal: true
+      without:
+      - nifi
+      size: small
+
+A profile without microservices uses the ones of its base profile, default
+or minimal. A profile can also define the microservices in the same format
+as ~/.swctl/default.yaml.
+`
+
+func newProfilesCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Command {
+	var outFmt output.Format
+
+	cmd := &cobra.Command{
+		Use:               "profiles",
+		Short:             "show the install and instance profiles",
+		Long:              profilesHelp,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profiles, err := config.ListProfiles(config.GetProfilesPath())
+			if err != nil {
+				return err
+			}
+			return outFmt.Write(out, newProfilesWriter(profiles))
+		},
+	}
+
+	bindOutputFlag(cmd, &outFmt)
+
+	return cmd
+}
+
+type profilesWriter struct {
+	Profiles []config.Profile `json:"profiles"`
+}
+
+func newProfilesWriter(profiles []config.Profile) *profilesWriter {
+	return &profilesWriter{Profiles: profiles}
+}
+
+func (i *profilesWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	table.MaxColWidth = 60
+	table.AddRow("PROFILE", "SOURCE", "BASE", "FUNCTIONAL AREAS", "INFRASTRUCTURE", "DESCRIPTION")
+	for _, prof := range i.Profiles {
+		source := "user"
+		if prof.BuiltIn {
+			source = "built-in"
+		}
+		areas := "all"
+		if len(prof.Metadata.FunctionalAreas) > 0 {
+			areas = strings.Join(prof.Metadata.FunctionalAreas, ", ")
+		}
+		table.AddRow(prof.Name, source, strings.ToLower(string(prof.Metadata.Base)), areas,
+			renderProfileInfrastructure(prof), prof.Metadata.Description)
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (i *profilesWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, i)
+}
+
+func (i *profilesWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, i)
+}
+
+// renderProfileInfrastructure renders the infrastructure toggles of a profile
+func renderProfileInfrastructure(prof config.Profile) string {
+	infra := prof.Metadata.Infrastructure
+	var toggles []string
+	if infra.Minimal {
+		toggles = append(toggles, "minimal")
+	}
+	if infra.Size != "" {
+		toggles = append(toggles, "size "+infra.Size)
+	}
+	if len(infra.With) > 0 {
+		toggles = append(toggles, "with "+strings.Join(infra.With, ","))
+	}
+	if len(infra.Without) > 0 {
+		toggles = append(toggles, "without "+strings.Join(infra.Without, ","))
+	}
+	if len(toggles) == 0 {
+		return "-"
+	}
+	return strings.Join(toggles, "; ")
+}
@@ -0,0 +1,157 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/gookit/color"
+	"github.com/gosuri/uitable"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/sitewhere/swctl/pkg/action"
+	"github.com/sitewhere/swctl/pkg/install"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	helmAction "helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/output"
+)
+
+var rollbackHelp = `
+Use this command to roll back the SiteWhere Infrastructure release to a
+previous revision. Without a revision, the release is rolled back to the
+revision before the current one. After the rollback, the SiteWhere
+components are checked in the same way as check-install until they are
+ready or the timeout expires.
+
+To list the revisions of the release use:
+
+  swctl rollback --list
+
+To roll back to the revision 3 and wait for the resources to be ready use:
+
+  swctl rollback 3 --wait --timeout 10m
+`
+
+func newRollbackCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewRollback(cfg, settings)
+	var outFmt output.Format
+	var list bool
+
+	cmd := &cobra.Command{
+		Use:               "rollback [REVISION]",
+		Short:             "Roll back SiteWhere Infrastructure to a previous revision",
+		Long:              rollbackHelp,
+		Args:              require.MaximumNArgs(1),
+		ValidArgsFunction: noCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if list {
+				revisions, err := client.Revisions()
+				if err != nil {
+					return err
+				}
+				return outFmt.Write(out, newRevisionsWriter(revisions))
+			}
+			if len(args) > 0 {
+				revision, err := strconv.Atoi(args[0])
+				if err != nil || revision < 1 {
+					return errors.Errorf("invalid revision '%s'", args[0])
+				}
+				client.Revision = revision
+			}
+			if outFmt == output.Table {
+				client.Progress = newInstallProgress(out)
+			}
+			results, err := client.Run()
+			if err != nil {
+				return err
+			}
+			if err = outFmt.Write(out, newRollbackWriter(results)); err != nil {
+				return err
+			}
+			if !results.Complete() {
+				return errors.New("SiteWhere installation is not complete after the rollback")
+			}
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+
+	f.BoolVarP(&list, "list", "l", false, "List the revisions of the release.")
+	f.BoolVarP(&client.Wait, "wait", "w", client.Wait, "Wait for the resources to be ready before checking the components.")
+	f.DurationVar(&client.Timeout, "timeout", client.Timeout, "Time to wait for the rollback, and then for the components to be ready.")
+
+	bindOutputFlag(cmd, &outFmt)
+
+	return cmd
+}
+
+type rollbackWriter struct {
+	Results *install.SiteWhereRollback `json:"results"`
+}
+
+func newRollbackWriter(results *install.SiteWhereRollback) *rollbackWriter {
+	return &rollbackWriter{Results: results}
+}
+
+func (i *rollbackWriter) WriteTable(out io.Writer) error {
+	fmt.Fprintf(out, "Rolled back %s from revision %d to revision %d (new revision %d)\n\n",
+		i.Results.Release, i.Results.PreviousRevision, i.Results.TargetRevision, i.Results.Revision)
+	table := uitable.New()
+	table.AddRow("COMPONENT", "KIND", "STATUS", "DETAIL")
+	for _, component := range i.Results.Components {
+		table.AddRow(component.Name, component.Kind, renderInstallStatus(component.Status), component.Detail)
+	}
+	if i.Results.Complete() {
+		table.AddRow(color.Style{color.FgGreen, color.OpBold}.Render("SiteWhere 3.0 Rolled back"))
+	} else {
+		table.AddRow(color.Style{color.FgRed, color.OpBold}.Render("SiteWhere 3.0 Rolled back, some components are not ready"))
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (i *rollbackWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, i)
+}
+
+func (i *rollbackWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, i)
+}
+
+type revisionsWriter struct {
+	Revisions []install.ReleaseRevision `json:"revisions"`
+}
+
+func newRevisionsWriter(revisions []install.ReleaseRevision) *revisionsWriter {
+	return &revisionsWriter{Revisions: revisions}
+}
+
+func (i *revisionsWriter) WriteTable(out io.Writer) error {
+	return output.EncodeTable(out, revisionsTable(i.Revisions))
+}
+
+func (i *revisionsWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, i)
+}
+
+func (i *revisionsWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, i)
+}
@@ -38,6 +38,8 @@ func newRootCmd(actionConfig *action.Configuration, out io.Writer, args []string
 	}
 	flags := cmd.PersistentFlags()
 
+	settings.AddFlags(flags)
+
 	// Command completion
 	flags.ParseErrorsWhitelist.UnknownFlags = true
 	flags.Parse(args)
@@ -45,13 +47,25 @@ func newRootCmd(actionConfig *action.Configuration, out io.Writer, args []string
 	// Add subcommands
 	cmd.AddCommand(
 		newInstallCmd(actionConfig, out),
+		newUpgradeCmd(actionConfig, out),
+		newRollbackCmd(actionConfig, out),
+		newTemplateCmd(actionConfig, out),
 		newCheckInstallCmd(actionConfig, out),
+		newStatusCmd(actionConfig, out),
+		newPreflightCmd(actionConfig, out),
 		newCreateCmd(actionConfig, out),
+		newApplyCmd(actionConfig, out),
 		newDeleteCmd(actionConfig, out),
+		newUpdateCmd(actionConfig, out),
+		newScaleCmd(actionConfig, out),
 		newInstancesCmd(actionConfig, out),
 		newUninstallCmd(actionConfig, out),
 		newLogsCmd(actionConfig, out),
+		newDebugCmd(actionConfig, out),
+		newPortForwardCmd(actionConfig, out),
 		newLogLevelCmd(actionConfig, out),
+		newConfigCmd(actionConfig, out),
+		newProfilesCmd(actionConfig, out),
 		newCompletionCmd(out),
 		newVersionCmd(out))
 
@@ -0,0 +1,116 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"github.com/sitewhere/swctl/pkg/action"
+	"github.com/sitewhere/swctl/pkg/instance"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	helmAction "helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/output"
+)
+
+var scaleHelp = `
+Use this command to set the number of replicas of SiteWhere Microservices.
+
+To run 3 replicas of the event-sources and inbound-processing microservices use:
+
+  swctl scale sitewhere event-sources inbound-processing --replicas 3
+
+To scale every microservice of the instance and wait for the replicas to be
+available use:
+
+  swctl scale sitewhere --all --replicas 2 --wait
+
+To stop the event-sources microservice use:
+
+  swctl scale sitewhere event-sources --replicas 0
+`
+
+func newScaleCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewScale(cfg)
+	var outFmt output.Format
+
+	cmd := &cobra.Command{
+		Use:   "scale INSTANCE [MS...] --replicas N",
+		Short: "set the number of replicas of SiteWhere Microservices",
+		Long:  scaleHelp,
+		Args:  require.MinimumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return compListInstances(toComplete, cfg)
+			}
+			return compListMicroservices(toComplete, args[0], cfg)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client.InstanceName = args[0]
+			client.Microservices = args[1:]
+			if outFmt == output.Table {
+				client.Progress = newInstallProgress(out)
+			}
+			results, err := client.Run()
+			if err != nil {
+				if results != nil && len(results.Microservices) > 0 {
+					outFmt.Write(out, newScaleWriter(results))
+				}
+				return err
+			}
+			return outFmt.Write(out, newScaleWriter(results))
+		},
+	}
+
+	f := cmd.Flags()
+	f.Int32VarP(&client.Replicas, "replicas", "r", client.Replicas, "Number of replicas, 0 stops the microservices.")
+	f.BoolVar(&client.All, "all", client.All, "Scale every microservice of the instance.")
+	f.BoolVarP(&client.Wait, "wait", "w", client.Wait, "Wait for the replicas to be available.")
+	f.DurationVar(&client.Timeout, "timeout", client.Timeout, "Time to wait for each microservice to be available.")
+	cmd.MarkFlagRequired("replicas")
+	bindOutputFlag(cmd, &outFmt)
+
+	return cmd
+}
+
+type scaleWriter struct {
+	Results *instance.ScaleSiteWhereInstance `json:"results"`
+}
+
+func newScaleWriter(results *instance.ScaleSiteWhereInstance) *scaleWriter {
+	return &scaleWriter{Results: results}
+}
+
+func (i *scaleWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	table.AddRow("MICROSERVICE", "BEFORE", "AFTER")
+	for _, ms := range i.Results.Microservices {
+		table.AddRow(ms.Name, ms.Before, ms.After)
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (i *scaleWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, i)
+}
+
+func (i *scaleWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, i)
+}
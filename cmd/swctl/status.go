@@ -0,0 +1,145 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"github.com/sitewhere/swctl/pkg/action"
+	"github.com/sitewhere/swctl/pkg/install"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	helmAction "helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/output"
+)
+
+var statusHelp = `
+Use this command to show the SiteWhere 3.0 installed on a Kubernetes Cluster.
+This command will show:
+ - SiteWhere Helm release status, chart version and app version.
+ - SiteWhere Helm release revision history.
+ - Notes of the last deployment.
+ - SiteWhere Operator image.
+ - SiteWhere Custom Resources Definitions versions.
+
+To show the last 5 revisions use:
+
+  swctl history --max 5
+`
+
+func newStatusCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewReleaseStatus(cfg, settings)
+	var outFmt output.Format
+
+	cmd := &cobra.Command{
+		Use:               "status",
+		Short:             "Show the status and history of the SiteWhere release",
+		Aliases:           []string{"history"},
+		Long:              statusHelp,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			results, err := client.Run()
+			if err != nil {
+				return err
+			}
+			return outFmt.Write(out, newStatusWriter(results))
+		},
+	}
+
+	f := cmd.Flags()
+
+	f.IntVar(&client.Max, "max", client.Max, "Maximum number of revisions to show.")
+
+	bindOutputFlag(cmd, &outFmt)
+
+	return cmd
+}
+
+type statusWriter struct {
+	Results *install.SiteWhereReleaseStatus `json:"results"`
+}
+
+func newStatusWriter(results *install.SiteWhereReleaseStatus) *statusWriter {
+	return &statusWriter{Results: results}
+}
+
+func (i *statusWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	table.AddRow("Release:", color.Info.Render(i.Results.Release))
+	table.AddRow("Namespace:", i.Results.Namespace)
+	table.AddRow("Status:", i.Results.Status)
+	table.AddRow("Revision:", i.Results.Revision)
+	table.AddRow("Last Deployed:", i.Results.LastDeployed.Format("2006-01-02 15:04:05"))
+	table.AddRow("Chart Version:", i.Results.ChartVersion)
+	table.AddRow("App Version:", i.Results.AppVersion)
+	table.AddRow("Operator Image:", valueOrNone(i.Results.OperatorImage))
+	if err := output.EncodeTable(out, table); err != nil {
+		return err
+	}
+
+	crds := uitable.New()
+	crds.AddRow("CRD", "VERSION", "SERVED", "STORAGE")
+	for _, crd := range i.Results.CRDs {
+		crds.AddRow(crd.Name, crd.Version, crd.Served, crd.Storage)
+	}
+	fmt.Fprintln(out)
+	if err := output.EncodeTable(out, crds); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out)
+	if err := output.EncodeTable(out, revisionsTable(i.Results.History)); err != nil {
+		return err
+	}
+
+	if notes := strings.TrimSpace(i.Results.Notes); notes != "" {
+		fmt.Fprintf(out, "\nNOTES:\n%s\n", notes)
+	}
+	return nil
+}
+
+func (i *statusWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, i)
+}
+
+func (i *statusWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, i)
+}
+
+func revisionsTable(revisions []install.ReleaseRevision) *uitable.Table {
+	table := uitable.New()
+	table.AddRow("REVISION", "UPDATED", "STATUS", "CHART", "APP VERSION", "DESCRIPTION")
+	for _, revision := range revisions {
+		table.AddRow(revision.Revision, revision.Updated.Format("2006-01-02 15:04:05"), revision.Status,
+			revision.Chart, revision.AppVersion, revision.Description)
+	}
+	return table
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
@@ -27,18 +27,19 @@ import (
 	"github.com/spf13/pflag"
 	"k8s.io/klog"
 
-	"github.com/sitewhere/swctl/pkg/resources"
+	"github.com/sitewhere/swctl/pkg/cli"
 
 	"helm.sh/helm/v3/pkg/action"
-	"helm.sh/helm/v3/pkg/cli"
 )
 
 var settings *cli.EnvSettings
 
 func init() {
-	os.Setenv("HELM_NAMESPACE", resources.SitewhereSystemNamespace())
-	settings = cli.New()
+	var err error
 	log.SetFlags(log.Lshortfile)
+	if settings, err = cli.New(); err != nil {
+		log.Fatal(err)
+	}
 }
 
 func debug(format string, v ...interface{}) {
@@ -68,7 +69,7 @@ func main() {
 
 	// run when each command's execute method is called
 	cobra.OnInitialize(func() {
-		if err := actionConfig.Init(settings.RESTClientGetter(), settings.Namespace(), os.Getenv("HELM_DRIVER"), debug); err != nil {
+		if err := actionConfig.Init(settings.RESTClientGetter(), settings.SystemNamespace, os.Getenv("HELM_DRIVER"), debug); err != nil {
 			log.Fatal(err)
 		}
 	})
@@ -27,9 +27,9 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/sitewhere/swctl/internal/test"
+	"github.com/sitewhere/swctl/pkg/cli"
 
 	helmAction "helm.sh/helm/v3/pkg/action"
-	"helm.sh/helm/v3/pkg/cli"
 	kubefake "helm.sh/helm/v3/pkg/kube/fake"
 )
 
@@ -113,6 +113,6 @@ func resetEnv() func() {
 			kv := strings.SplitN(pair, "=", 2)
 			os.Setenv(kv[0], kv[1])
 		}
-		settings = cli.New()
+		settings, _ = cli.New()
 	}
 }
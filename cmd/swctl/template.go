@@ -0,0 +1,110 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/sitewhere/swctl/pkg/action"
+	"github.com/sitewhere/swctl/pkg/install"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	helmAction "helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/output"
+)
+
+var templateHelp = `
+Use this command to render the manifests that 'swctl install' would apply,
+without contacting the Kubernetes Cluster. The merged values, the Custom
+Resource Definitions and the rendered manifests are written to stdout,
+or to a directory with --output-dir.
+
+If the chart version was already downloaded, the locally cached chart is used.
+`
+
+func newTemplateCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewInstall(cfg, settings)
+	var outFmt output.Format
+
+	cmd := &cobra.Command{
+		Use:               "template",
+		Short:             "Render SiteWhere install manifests locally",
+		Long:              templateHelp,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			results, err := client.Template()
+			if err != nil {
+				return err
+			}
+			return outFmt.Write(out, newTemplateWriter(results))
+		},
+	}
+
+	f := cmd.Flags()
+
+	f.BoolVar(&client.SkipCRD, "skip-crd", false, "Skip Custom Resource Definition rendering.")
+	f.StringVar(&client.OutputDir, "output-dir", "", "Write the rendered manifests to this directory instead of stdout.")
+	addInfrastructureValuesFlags(f, &client.InfrastructureValues)
+	addChartSourceFlags(f, &client.ChartSource)
+
+	bindOutputFlag(cmd, &outFmt)
+
+	return cmd
+}
+
+type templateWriter struct {
+	Results *install.SiteWhereTemplate `json:"results"`
+}
+
+func newTemplateWriter(results *install.SiteWhereTemplate) *templateWriter {
+	return &templateWriter{Results: results}
+}
+
+func (i *templateWriter) WriteTable(out io.Writer) error {
+	if i.Results.OutputDir != "" {
+		table := uitable.New()
+		table.AddRow("RELEASE", "CHART VERSION", "OUTPUT DIR")
+		table.AddRow(i.Results.Release, i.Results.ChartVersion, i.Results.OutputDir)
+		return output.EncodeTable(out, table)
+	}
+	values, err := yaml.Marshal(i.Results.Values)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "---\n# Values of %s (chart version %s)\n", i.Results.Release, i.Results.ChartVersion)
+	for _, line := range strings.Split(strings.TrimRight(string(values), "\n"), "\n") {
+		fmt.Fprintf(out, "# %s\n", line)
+	}
+	fmt.Fprint(out, i.Results.CRDs)
+	fmt.Fprint(out, i.Results.Manifest)
+	return nil
+}
+
+func (i *templateWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, i)
+}
+
+func (i *templateWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, i)
+}
@@ -17,7 +17,10 @@
 package main
 
 import (
+	"bufio"
+	"fmt"
 	"io"
+	"strings"
 
 	"github.com/gookit/color"
 	"github.com/gosuri/uitable"
@@ -25,6 +28,7 @@ import (
 
 	"github.com/sitewhere/swctl/pkg/action"
 	"github.com/sitewhere/swctl/pkg/install"
+	"github.com/sitewhere/swctl/pkg/status"
 
 	"helm.sh/helm/v3/cmd/helm/require"
 	helmAction "helm.sh/helm/v3/pkg/action"
@@ -39,11 +43,18 @@ This command will uninstall:
  - SiteWhere Templates.
  - SiteWhere Operator.
  - SiteWhere Infrastructure.
+
+Use --purge to delete every resource owned by SiteWhere: the instances,
+their namespaces and persistent volume claims, the labeled cluster roles
+and bindings, and the sitewhere.io Custom Resource Definitions. The
+resources are listed and a confirmation is asked before deleting them,
+use --yes to skip the confirmation.
 `
 
 func newUninstallCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewUninstall(cfg, settings)
 	var outFmt output.Format
+	var yes bool
 
 	cmd := &cobra.Command{
 		Use:               "uninstall",
@@ -52,8 +63,35 @@ func newUninstallCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Comman
 		Args:              require.NoArgs,
 		ValidArgsFunction: noCompletions,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			results, err := client.Run()
+			if !client.Purge {
+				results, err := client.Run()
+				if err != nil {
+					return err
+				}
+				return outFmt.Write(out, newUninstallWriter(results))
+			}
+			if !yes && outFmt != output.Table {
+				return fmt.Errorf("--yes is required to purge with --output %s", outFmt)
+			}
+			inventory, err := client.Inventory()
+			if err != nil {
+				return err
+			}
+			if !yes {
+				if err := output.Table.Write(out, newInventoryWriter(inventory)); err != nil {
+					return err
+				}
+				if !confirm(cmd.InOrStdin(), out, "Delete these resources?") {
+					return fmt.Errorf("uninstall aborted")
+				}
+			}
+			results, err := client.RunPurge(inventory)
 			if err != nil {
+				if results != nil {
+					if writeErr := outFmt.Write(out, newUninstallWriter(results)); writeErr != nil {
+						return writeErr
+					}
+				}
 				return err
 			}
 			return outFmt.Write(out, newUninstallWriter(results))
@@ -63,6 +101,8 @@ func newUninstallCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Comman
 	f := cmd.Flags()
 
 	f.BoolVarP(&client.Purge, "purge", "p", false, "Purge data.")
+	f.BoolVarP(&yes, "yes", "y", false, "Do not ask for confirmation before purging.")
+	f.DurationVar(&client.Timeout, "timeout", client.Timeout, "Time to wait for the namespaces to terminate when purging.")
 
 	bindOutputFlag(cmd, &outFmt)
 
@@ -79,6 +119,20 @@ func newUninstallWriter(results *install.SiteWhereInstall) *uninstallWriter {
 
 func (i *uninstallWriter) WriteTable(out io.Writer) error {
 	table := uitable.New()
+	if len(i.Results.Components) > 0 {
+		table.AddRow("KIND", "NAME", "STATUS")
+		for _, component := range i.Results.Components {
+			table.AddRow(component.Kind, component.Name, renderDeletedStatus(component))
+		}
+		table.AddRow("")
+	}
+	if len(i.Results.Errors) > 0 {
+		table.AddRow(color.Style{color.FgRed, color.OpBold}.Render("SiteWhere 3.0 Uninstall incomplete"))
+		for _, err := range i.Results.Errors {
+			table.AddRow(color.Error.Render(err))
+		}
+		return output.EncodeTable(out, table)
+	}
 	table.AddRow(color.Style{color.FgGreen, color.OpBold}.Render("SiteWhere 3.0 Uninstalled"))
 	return output.EncodeTable(out, table)
 }
@@ -90,3 +144,46 @@ func (i *uninstallWriter) WriteJSON(out io.Writer) error {
 func (i *uninstallWriter) WriteYAML(out io.Writer) error {
 	return output.EncodeYAML(out, i)
 }
+
+func renderDeletedStatus(component status.SiteWhereStatus) string {
+	if component.Status == status.Uninstalled {
+		return color.Info.Render("Deleted")
+	}
+	return color.Error.Render(component.Detail)
+}
+
+type inventoryWriter struct {
+	Inventory *install.SiteWhereInventory `json:"inventory"`
+}
+
+func newInventoryWriter(inventory *install.SiteWhereInventory) *inventoryWriter {
+	return &inventoryWriter{Inventory: inventory}
+}
+
+func (i *inventoryWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	table.AddRow("KIND", "NAME", "NAMESPACE")
+	for _, item := range i.Inventory.Items {
+		table.AddRow(item.Kind, item.Name, item.Namespace)
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (i *inventoryWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, i)
+}
+
+func (i *inventoryWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, i)
+}
+
+// confirm asks a yes/no question, answering no by default
+func confirm(in io.Reader, out io.Writer, question string) bool {
+	fmt.Fprintf(out, "%s [y/N] ", question)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,151 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gookit/color"
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"github.com/sitewhere/swctl/pkg/action"
+	"github.com/sitewhere/swctl/pkg/instance"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	helmAction "helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/output"
+)
+
+var updateInstanceDesc = `
+Use this command to update an Instance of SiteWhere and its microservices.
+Only the settings given are changed. The changes are shown and then applied
+to the Instance and Microservice Custom Resources.
+
+To update the images of the instance "sitewhere" to 3.0.6 use:
+
+  swctl update instance sitewhere --tag 3.0.6
+
+To show the changes without applying them use:
+
+  swctl update instance sitewhere --tag 3.0.6 --replicas 2 --dry-run
+
+To update the microservices one at a time, instance-management first, waiting
+for each one to be available and reverting their images and replicas if one
+is not, use:
+
+  swctl update instance sitewhere --tag 3.0.6 --rolling --timeout 10m
+`
+
+func newUpdateInstanceCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewUpdateInstance(cfg)
+	var outFmt output.Format
+	var replicas int32
+
+	cmd := &cobra.Command{
+		Use:   "instance [NAME]",
+		Short: "update an instance",
+		Long:  updateInstanceDesc,
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return compListInstances(toComplete, cfg)
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed("replicas") {
+				client.Replicas = &replicas
+			}
+			client.InstanceName = args[0]
+			if outFmt == output.Table {
+				client.Progress = newInstallProgress(out)
+			}
+			results, err := client.Run()
+			if err != nil {
+				if results != nil && len(results.Steps) > 0 {
+					outFmt.Write(out, newUpdateInstanceWriter(results))
+				}
+				return err
+			}
+			return outFmt.Write(out, newUpdateInstanceWriter(results))
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&client.Tag, "tag", "t", client.Tag, "Docker image tag.")
+	f.StringVar(&client.Registry, "registry", client.Registry, "Docker image registry.")
+	f.Int32VarP(&replicas, "replicas", "r", replicas, "Number of replicas, at least 1.")
+	f.StringVarP(&client.ConfigurationTemplate, "config-template", "c", client.ConfigurationTemplate, "Configuration template.")
+	f.StringVarP(&client.DatasetTemplate, "dateset-template", "x", client.DatasetTemplate, "Dataset template.")
+	f.StringSliceVar(&client.DebugMicroservices, "debug-ms", client.DebugMicroservices, "Functional areas of the microservices whose debug mode is enabled.")
+	f.BoolVar(&client.DryRun, "dry-run", client.DryRun, "Show the changes without applying them.")
+	f.BoolVar(&client.Rolling, "rolling", client.Rolling, "Update the microservices one at a time, reverting their images and replicas if one is not available.")
+	f.StringSliceVar(&client.Order, "order", client.Order, "Functional areas updated first in a rolling update, the others follow in alphabetical order.")
+	f.DurationVar(&client.Timeout, "timeout", client.Timeout, "Time to wait for each microservice to be available in a rolling update.")
+	bindOutputFlag(cmd, &outFmt)
+
+	return cmd
+}
+
+type updateInstanceWriter struct {
+	Results *instance.UpdateSiteWhereInstance `json:"results"`
+}
+
+func newUpdateInstanceWriter(results *instance.UpdateSiteWhereInstance) *updateInstanceWriter {
+	return &updateInstanceWriter{Results: results}
+}
+
+func (i *updateInstanceWriter) WriteTable(out io.Writer) error {
+	if len(i.Results.Changes) == 0 {
+		_, err := fmt.Fprintf(out, "Instance %s is up to date.\n", i.Results.InstanceName)
+		return err
+	}
+	table := uitable.New()
+	table.AddRow("KIND", "NAME", "FIELD", "FROM", "TO")
+	for _, change := range i.Results.Changes {
+		table.AddRow(change.Kind, change.Name, change.Field, color.Red.Render(valueOrNone(change.From)), color.Green.Render(valueOrNone(change.To)))
+	}
+	if err := output.EncodeTable(out, table); err != nil {
+		return err
+	}
+	if len(i.Results.Steps) > 0 {
+		steps := uitable.New()
+		steps.AddRow("MICROSERVICE", "STATUS", "DETAIL")
+		for _, step := range i.Results.Steps {
+			steps.AddRow(step.Name, renderInstallStatus(step.Status), step.Detail)
+		}
+		if err := output.EncodeTable(out, steps); err != nil {
+			return err
+		}
+	}
+	var status = color.Info.Render("Updated")
+	if i.Results.DryRun {
+		status = color.Warn.Render("Not updated (dry run)")
+	}
+	_, err := fmt.Fprintf(out, "Instance %s: %s\n", i.Results.InstanceName, status)
+	return err
+}
+
+func (i *updateInstanceWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, i)
+}
+
+func (i *updateInstanceWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, i)
+}
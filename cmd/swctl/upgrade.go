@@ -0,0 +1,127 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gookit/color"
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"github.com/sitewhere/swctl/pkg/action"
+	"github.com/sitewhere/swctl/pkg/install"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	helmAction "helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli/output"
+)
+
+var upgradeHelp = `
+Use this command to upgrade SiteWhere 3.0 installed on a Kubernetes Cluster.
+The release is upgraded in place to the given SiteWhere Infrastructure
+Helm Chart version. The chart version and values changes are shown
+before applying them.
+
+For example, to upgrade to the chart version 0.1.14 use:
+
+  swctl upgrade --chart-version 0.1.14
+
+To show the changes without applying them use:
+
+  swctl upgrade --chart-version 0.1.14 --dry-run
+`
+
+func newUpgradeCmd(cfg *helmAction.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewUpgrade(cfg, settings)
+	var outFmt output.Format
+
+	cmd := &cobra.Command{
+		Use:               "upgrade",
+		Short:             "Upgrade SiteWhere CRD, Operators and Infrastructure",
+		Long:              upgradeHelp,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client.FlagChanged = cmd.Flags().Changed
+			results, err := client.Run()
+			if err != nil {
+				return err
+			}
+			return outFmt.Write(out, newUpgradeWriter(results))
+		},
+	}
+
+	f := cmd.Flags()
+
+	f.BoolVarP(&client.WaitReady, "wait", "w", false, "Wait for components to be ready before return control.")
+	f.BoolVar(&client.ReuseValues, "reuse-values", false, "Reuse the values of the last release and merge in the new ones.")
+	f.BoolVar(&client.DryRun, "dry-run", false, "Show the changes without applying them.")
+	addInfrastructureValuesFlags(f, &client.InfrastructureValues)
+	addChartSourceFlags(f, &client.ChartSource)
+
+	bindOutputFlag(cmd, &outFmt)
+
+	return cmd
+}
+
+type upgradeWriter struct {
+	Results *install.SiteWhereUpgrade `json:"results"`
+}
+
+func newUpgradeWriter(results *install.SiteWhereUpgrade) *upgradeWriter {
+	return &upgradeWriter{Results: results}
+}
+
+func (i *upgradeWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	table.AddRow("RELEASE", "NAMESPACE", "REVISION", "CHART VERSION")
+	table.AddRow(i.Results.Release, i.Results.Namespace, i.Results.Revision,
+		fmt.Sprintf("%s -> %s", i.Results.PreviousChartVersion, i.Results.ChartVersion))
+	table.AddRow("")
+	if len(i.Results.ValueChanges) == 0 {
+		table.AddRow("No values changed")
+	} else {
+		table.AddRow("VALUE", "PREVIOUS", "NEW")
+		for _, change := range i.Results.ValueChanges {
+			table.AddRow(change.Key, renderValue(change.Previous), renderValue(change.Value))
+		}
+	}
+	table.AddRow("")
+	if i.Results.DryRun {
+		table.AddRow(color.Warn.Render("SiteWhere 3.0 Upgrade not applied (dry run)"))
+	} else {
+		table.AddRow(color.Style{color.FgGreen, color.OpBold}.Render("SiteWhere 3.0 Upgraded"))
+	}
+	return output.EncodeTable(out, table)
+}
+
+func renderValue(value interface{}) string {
+	if value == nil {
+		return "<none>"
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func (i *upgradeWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, i)
+}
+
+func (i *upgradeWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, i)
+}
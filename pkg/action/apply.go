@@ -0,0 +1,442 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	ctlcli "sigs.k8s.io/controller-runtime/pkg/client"
+
+	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
+
+	"github.com/sitewhere/swctl/pkg/apply"
+	"github.com/sitewhere/swctl/pkg/cli"
+
+	"helm.sh/helm/v3/pkg/action"
+)
+
+const (
+	// shorthandAPIVersion is the API version of the swctl shorthand documents
+	shorthandAPIVersion = "swctl.sitewhere.io/v1"
+	// instanceShorthandKind is the kind of the shorthand of an instance
+	instanceShorthandKind = "Instance"
+	// tenantShorthandKind is the kind of the shorthand of a tenant
+	tenantShorthandKind = "Tenant"
+	// stdinFilename is the filename used to read from stdin
+	stdinFilename = "-"
+)
+
+// InstanceShorthand is a SiteWhere instance described with the settings of
+// swctl create instance
+type InstanceShorthand struct {
+	Name                  string   `json:"name"`
+	Namespace             string   `json:"namespace,omitempty"`
+	Profile               string   `json:"profile,omitempty"`
+	Minimal               bool     `json:"minimal,omitempty"`
+	Tag                   string   `json:"tag,omitempty"`
+	Registry              string   `json:"registry,omitempty"`
+	Replicas              int32    `json:"replicas,omitempty"`
+	Debug                 bool     `json:"debug,omitempty"`
+	DebugMicroservices    []string `json:"debugMicroservices,omitempty"`
+	ConfigurationTemplate string   `json:"configurationTemplate,omitempty"`
+	DatasetTemplate       string   `json:"datasetTemplate,omitempty"`
+}
+
+// TenantShorthand is a SiteWhere tenant described with the settings of
+// swctl create tenant
+type TenantShorthand struct {
+	Name                  string   `json:"name"`
+	Instance              string   `json:"instance"`
+	AuthenticationToken   string   `json:"authenticationToken,omitempty"`
+	AuthorizedUserIds     []string `json:"authorizedUserIds,omitempty"`
+	ConfigurationTemplate string   `json:"configurationTemplate,omitempty"`
+	DatasetTemplate       string   `json:"datasetTemplate,omitempty"`
+}
+
+// applyDocument is a document of the applied file
+type applyDocument struct {
+	// Index of the document in the file, starting at 1
+	Index int
+	// APIVersion of the document
+	APIVersion string `json:"apiVersion"`
+	// Kind of the document
+	Kind string `json:"kind"`
+	// Raw is the document converted to JSON
+	Raw []byte `json:"-"`
+}
+
+// Apply is the action for creating or updating SiteWhere instances and
+// tenants from a YAML file
+type Apply struct {
+	cfg *action.Configuration
+
+	settings *cli.EnvSettings
+
+	// Filename of the YAML file, - for stdin
+	Filename string
+	// In is the reader of stdin
+	In io.Reader
+}
+
+// NewApply constructs a new *Apply
+func NewApply(cfg *action.Configuration, settings *cli.EnvSettings) *Apply {
+	return &Apply{
+		cfg:      cfg,
+		settings: settings,
+		Filename: "",
+		In:       os.Stdin,
+	}
+}
+
+// Run executes the apply command, returning the result of each object.
+func (i *Apply) Run() (*apply.SiteWhereApply, error) {
+	if i.Filename == "" {
+		return nil, errors.New("a filename is required, use - to read from stdin")
+	}
+	docs, err := i.readDocuments()
+	if err != nil {
+		return nil, err
+	}
+	if err := i.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	client, err := ControllerClient(i.cfg)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.TODO()
+	var result = &apply.SiteWhereApply{}
+	for _, doc := range docs {
+		obj, err := i.buildObject(ctx, client, doc)
+		if err != nil {
+			return result, errors.Wrapf(err, "document %d", doc.Index)
+		}
+		fields, err := documentFields(doc, obj)
+		if err != nil {
+			return result, errors.Wrapf(err, "document %d", doc.Index)
+		}
+		applied, err := applyObject(ctx, client, obj, fields)
+		if err != nil {
+			return result, errors.Wrapf(err, "document %d", doc.Index)
+		}
+		result.Objects = append(result.Objects, *applied)
+	}
+	return result, nil
+}
+
+func (i *Apply) readDocuments() ([]applyDocument, error) {
+	if i.Filename == stdinFilename {
+		return parseDocuments(i.In)
+	}
+	f, err := os.Open(i.Filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseDocuments(f)
+}
+
+// parseDocuments splits a multi-document YAML and checks that every
+// document is of a supported kind
+func parseDocuments(r io.Reader) ([]applyDocument, error) {
+	var result []applyDocument
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	for index := 1; ; index++ {
+		data, err := reader.Read()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(data)) == 0 {
+			index--
+			continue
+		}
+		raw, err := utilyaml.ToJSON(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "document %d", index)
+		}
+		if string(raw) == "null" {
+			index--
+			continue
+		}
+		var doc = applyDocument{Index: index, Raw: raw}
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return nil, errors.Wrapf(err, "document %d", index)
+		}
+		if !supportedDocument(doc) {
+			return nil, fmt.Errorf("document %d: unsupported kind '%s' of apiVersion '%s'", index, doc.Kind, doc.APIVersion)
+		}
+		result = append(result, doc)
+	}
+}
+
+func supportedDocument(doc applyDocument) bool {
+	switch doc.APIVersion {
+	case sitewhereiov1alpha4.GroupVersion.String():
+		return doc.Kind == sitewhereiov1alpha4.SiteWhereInstanceKind || doc.Kind == sitewhereiov1alpha4.SiteWhereTenantKind
+	case shorthandAPIVersion:
+		return doc.Kind == instanceShorthandKind || doc.Kind == tenantShorthandKind
+	}
+	return false
+}
+
+// buildObject builds the custom resource of a document
+func (i *Apply) buildObject(ctx context.Context, client ctlcli.Client, doc applyDocument) (runtime.Object, error) {
+	switch {
+	case doc.APIVersion == shorthandAPIVersion && doc.Kind == instanceShorthandKind:
+		var shorthand InstanceShorthand
+		if err := json.Unmarshal(doc.Raw, &shorthand); err != nil {
+			return nil, err
+		}
+		return i.buildInstance(&shorthand)
+	case doc.APIVersion == shorthandAPIVersion && doc.Kind == tenantShorthandKind:
+		var shorthand TenantShorthand
+		if err := json.Unmarshal(doc.Raw, &shorthand); err != nil {
+			return nil, err
+		}
+		return i.buildTenant(ctx, client, &shorthand)
+	}
+	return decodeObject(doc)
+}
+
+// decodeObject decodes a SiteWhereInstance or SiteWhereTenant document
+func decodeObject(doc applyDocument) (runtime.Object, error) {
+	if doc.Kind == sitewhereiov1alpha4.SiteWhereInstanceKind {
+		var swInstance sitewhereiov1alpha4.SiteWhereInstance
+		if err := json.Unmarshal(doc.Raw, &swInstance); err != nil {
+			return nil, err
+		}
+		if swInstance.GetName() == "" {
+			return nil, errors.New("metadata.name is required")
+		}
+		return &swInstance, nil
+	}
+	var swTenant sitewhereiov1alpha4.SiteWhereTenant
+	if err := json.Unmarshal(doc.Raw, &swTenant); err != nil {
+		return nil, err
+	}
+	if swTenant.GetName() == "" {
+		return nil, errors.New("metadata.name is required")
+	}
+	if swTenant.GetNamespace() == "" {
+		return nil, errors.New("metadata.namespace is required")
+	}
+	return &swTenant, nil
+}
+
+func (i *Apply) buildInstance(shorthand *InstanceShorthand) (runtime.Object, error) {
+	if shorthand.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	createInstance := NewCreateInstance(i.cfg, i.settings)
+	createInstance.InstanceName = shorthand.Name
+	createInstance.Namespace = shorthand.Namespace
+	createInstance.Profile = shorthand.Profile
+	createInstance.Minimal = shorthand.Minimal
+	createInstance.Debug = shorthand.Debug
+	createInstance.DebugMicroservices = shorthand.DebugMicroservices
+	createInstance.ConfigurationTemplate = shorthand.ConfigurationTemplate
+	createInstance.DatasetTemplate = shorthand.DatasetTemplate
+	if shorthand.Tag != "" {
+		createInstance.Tag = shorthand.Tag
+	}
+	if shorthand.Registry != "" {
+		createInstance.Registry = shorthand.Registry
+	}
+	if shorthand.Replicas != 0 {
+		createInstance.Replicas = shorthand.Replicas
+	}
+	createInstance.Out = os.Stderr
+	createInstance.setDefaults()
+	createInstance.ignoreNamespace()
+	prof, err := createInstance.loadProfile()
+	if err != nil {
+		return nil, err
+	}
+	return createInstance.buildCRSiteWhereInstace(prof)
+}
+
+func (i *Apply) buildTenant(ctx context.Context, client ctlcli.Client, shorthand *TenantShorthand) (runtime.Object, error) {
+	if shorthand.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	if shorthand.Instance == "" {
+		return nil, errors.New("instance is required")
+	}
+	createTenant := NewCreateTenant(i.cfg)
+	createTenant.InstanceName = shorthand.Instance
+	createTenant.TenantName = shorthand.Name
+	createTenant.AuthenticationToken = shorthand.AuthenticationToken
+	createTenant.AuthorizedUserIds = shorthand.AuthorizedUserIds
+	if shorthand.ConfigurationTemplate != "" {
+		createTenant.ConfigurationTemplate = shorthand.ConfigurationTemplate
+	}
+	if shorthand.DatasetTemplate != "" {
+		createTenant.DatasetTemplate = shorthand.DatasetTemplate
+	}
+	_, namespace, err := getInstance(ctx, i.cfg, client, shorthand.Instance)
+	if err != nil {
+		return nil, err
+	}
+	return createTenant.buildCRSiteWhereTenant(namespace), nil
+}
+
+// documentFields returns the fields of the document used to patch an
+// existing object. A custom resource patches only the fields set in the
+// file, a shorthand patches the whole object built from it.
+func documentFields(doc applyDocument, obj runtime.Object) (map[string]interface{}, error) {
+	if doc.APIVersion == shorthandAPIVersion {
+		return toUnstructured(obj)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(doc.Raw, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// applyObject creates the object, or patches the labels, annotations and
+// spec with the given fields when it already exists.
+func applyObject(ctx context.Context, client ctlcli.Client, obj runtime.Object, fields map[string]interface{}) (*apply.AppliedObject, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	result := &apply.AppliedObject{
+		Kind:      obj.GetObjectKind().GroupVersionKind().Kind,
+		Name:      accessor.GetName(),
+		Namespace: accessor.GetNamespace(),
+	}
+	existing, err := scheme.New(obj.GetObjectKind().GroupVersionKind())
+	if err != nil {
+		return nil, err
+	}
+	key := ctlcli.ObjectKey{Namespace: accessor.GetNamespace(), Name: accessor.GetName()}
+	if err := client.Get(ctx, key, existing); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		if err := client.Create(ctx, obj); err != nil {
+			return nil, err
+		}
+		result.Result = apply.Created
+		return result, nil
+	}
+	patch := applyPatch(fields)
+	changed, err := patchChanges(existing, patch)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		result.Result = apply.Unchanged
+		return result, nil
+	}
+	rawPatch, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Patch(ctx, existing, ctlcli.RawPatch(types.MergePatchType, rawPatch)); err != nil {
+		return nil, err
+	}
+	result.Result = apply.Configured
+	return result, nil
+}
+
+// applyPatch builds a JSON merge patch with the labels, annotations and
+// spec of the fields. Only the fields present are patched, including the
+// ones set to zero values, so the configuration filled in by the operator
+// is kept.
+func applyPatch(desired map[string]interface{}) map[string]interface{} {
+	var patch = map[string]interface{}{}
+	var metadata = map[string]interface{}{}
+	if objMeta, ok := desired["metadata"].(map[string]interface{}); ok {
+		for _, field := range []string{"labels", "annotations"} {
+			if value, ok := objMeta[field]; ok {
+				metadata[field] = value
+			}
+		}
+	}
+	if len(metadata) > 0 {
+		patch["metadata"] = metadata
+	}
+	if spec, ok := desired["spec"]; ok {
+		patch["spec"] = spec
+	}
+	return patch
+}
+
+// patchChanges returns true if merging the patch changes the object
+func patchChanges(obj runtime.Object, patch map[string]interface{}) (bool, error) {
+	current, err := toUnstructured(obj)
+	if err != nil {
+		return false, err
+	}
+	merged, err := toUnstructured(obj)
+	if err != nil {
+		return false, err
+	}
+	return !reflect.DeepEqual(current, mergePatch(merged, patch)), nil
+}
+
+func toUnstructured(obj runtime.Object) (map[string]interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// mergePatch merges a JSON merge patch (RFC 7386) into the target
+func mergePatch(target map[string]interface{}, patch map[string]interface{}) map[string]interface{} {
+	for key, value := range patch {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+		patchMap, ok := value.(map[string]interface{})
+		if !ok {
+			target[key] = value
+			continue
+		}
+		targetMap, ok := target[key].(map[string]interface{})
+		if !ok {
+			targetMap = map[string]interface{}{}
+		}
+		target[key] = mergePatch(targetMap, patchMap)
+	}
+	return target
+}
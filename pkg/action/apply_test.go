@@ -0,0 +1,194 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
+
+	"github.com/sitewhere/swctl/pkg/apply"
+)
+
+func TestParseDocuments(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		name     string
+		content  string
+		expected []string
+		err      bool
+	}{
+		{
+			name: "multi-document",
+			content: `---
+apiVersion: sitewhere.io/v1alpha4
+kind: SiteWhereInstance
+metadata:
+  name: sitewhere
+---
+# empty document
+---
+apiVersion: swctl.sitewhere.io/v1
+kind: Tenant
+name: acme
+instance: sitewhere
+`,
+			expected: []string{"SiteWhereInstance", "Tenant"},
+		},
+		{
+			name:    "unsupported-kind",
+			content: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm\n",
+			err:     true,
+		},
+		{
+			name:    "invalid-yaml",
+			content: "apiVersion: [sitewhere.io\n",
+			err:     true,
+		},
+	}
+	for _, single := range data {
+		t.Run(single.name, func(single struct {
+			name     string
+			content  string
+			expected []string
+			err      bool
+		}) func(t *testing.T) {
+			return func(t *testing.T) {
+				docs, err := parseDocuments(strings.NewReader(single.content))
+				if single.err {
+					if err == nil {
+						t.Fatalf("expected error")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf(err.Error())
+				}
+				if len(docs) != len(single.expected) {
+					t.Fatalf("expected %d documents, got %d", len(single.expected), len(docs))
+				}
+				for i, kind := range single.expected {
+					if docs[i].Kind != kind || docs[i].Index != i+1 {
+						t.Fatalf("expected document %d of kind %s, got %d of kind %s", i+1, kind, docs[i].Index, docs[i].Kind)
+					}
+				}
+			}
+		}(single))
+	}
+}
+
+func TestApplyObject(t *testing.T) {
+	tenant := func(token string) *sitewhereiov1alpha4.SiteWhereTenant {
+		return &sitewhereiov1alpha4.SiteWhereTenant{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       sitewhereiov1alpha4.SiteWhereTenantKind,
+				APIVersion: sitewhereiov1alpha4.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: "acme", Namespace: "sitewhere"},
+			Spec: sitewhereiov1alpha4.SiteWhereTenantSpec{
+				Name:                "acme",
+				AuthenticationToken: token,
+			},
+		}
+	}
+	client := ctlfake.NewFakeClientWithScheme(scheme)
+	ctx := context.TODO()
+	steps := []struct {
+		token    string
+		expected apply.Result
+	}{
+		{token: "token1", expected: apply.Created},
+		{token: "token1", expected: apply.Unchanged},
+		{token: "token2", expected: apply.Configured},
+	}
+	for _, step := range steps {
+		obj := tenant(step.token)
+		fields, err := toUnstructured(obj)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		result, err := applyObject(ctx, client, obj, fields)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if result.Result != step.expected {
+			t.Fatalf("expected %s, got %s", step.expected, result.Result)
+		}
+	}
+	var swTenant sitewhereiov1alpha4.SiteWhereTenant
+	if err := client.Get(ctx, types.NamespacedName{Name: "acme", Namespace: "sitewhere"}, &swTenant); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if swTenant.Spec.AuthenticationToken != "token2" {
+		t.Fatalf("expected token2, got %s", swTenant.Spec.AuthenticationToken)
+	}
+}
+
+func TestApplyObjectZeroValues(t *testing.T) {
+	existing := &sitewhereiov1alpha4.SiteWhereTenant{
+		ObjectMeta: metav1.ObjectMeta{Name: "acme", Namespace: "sitewhere"},
+		Spec: sitewhereiov1alpha4.SiteWhereTenantSpec{
+			Name:                "acme",
+			AuthenticationToken: "token1",
+			DatasetTemplate:     "construction",
+		},
+	}
+	client := ctlfake.NewFakeClientWithScheme(scheme, existing)
+	docs, err := parseDocuments(strings.NewReader(`apiVersion: sitewhere.io/v1alpha4
+kind: SiteWhereTenant
+metadata:
+  name: acme
+  namespace: sitewhere
+spec:
+  authenticationToken: ""
+`))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	obj, err := decodeObject(docs[0])
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	fields, err := documentFields(docs[0], obj)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	ctx := context.TODO()
+	result, err := applyObject(ctx, client, obj, fields)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if result.Result != apply.Configured {
+		t.Fatalf("expected %s, got %s", apply.Configured, result.Result)
+	}
+	var swTenant sitewhereiov1alpha4.SiteWhereTenant
+	if err := client.Get(ctx, types.NamespacedName{Name: "acme", Namespace: "sitewhere"}, &swTenant); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if swTenant.Spec.AuthenticationToken != "" {
+		t.Fatalf("expected the token set to an empty value in the file to be cleared, got %s", swTenant.Spec.AuthenticationToken)
+	}
+	if swTenant.Spec.DatasetTemplate != "construction" || swTenant.Spec.Name != "acme" {
+		t.Fatalf("expected the fields not set in the file to be kept, got %+v", swTenant.Spec)
+	}
+}
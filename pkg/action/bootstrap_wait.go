@@ -0,0 +1,244 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	ctlcli "sigs.k8s.io/controller-runtime/pkg/client"
+
+	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
+
+	"github.com/sitewhere/swctl/pkg/status"
+)
+
+const (
+	// defaultBootstrapTimeout is the default time to wait for an instance
+	// or a tenant to bootstrap
+	defaultBootstrapTimeout = 600 * time.Second
+	// tenantLabel is the label of the tenant engines of a tenant
+	tenantLabel = "sitewhere.io/tenant"
+	// tenantManagementComponent is the bootstrap component of tenant management
+	tenantManagementComponent = "tenant-management"
+	// userManagementComponent is the bootstrap component of user management
+	userManagementComponent = "user-management"
+)
+
+// BootstrapProgressFunc is called when the bootstrap state of a component changes
+type BootstrapProgressFunc func(kind string, name string, state sitewhereiov1alpha4.BootstrapState)
+
+// bootstrapWaiter polls the bootstrap state of SiteWhere components and the
+// status of the microservice Deployments, reporting their changes
+type bootstrapWaiter struct {
+	interval          time.Duration
+	progress          ProgressFunc
+	bootstrapProgress BootstrapProgressFunc
+
+	states    map[string]sitewhereiov1alpha4.BootstrapState
+	workloads map[string]status.SiteWhereStatus
+}
+
+func newBootstrapWaiter(interval time.Duration, progress ProgressFunc, bootstrapProgress BootstrapProgressFunc) *bootstrapWaiter {
+	return &bootstrapWaiter{
+		interval:          interval,
+		progress:          progress,
+		bootstrapProgress: bootstrapProgress,
+		states:            map[string]sitewhereiov1alpha4.BootstrapState{},
+		workloads:         map[string]status.SiteWhereStatus{},
+	}
+}
+
+// state records the bootstrap state of a component, reporting it if changed
+func (w *bootstrapWaiter) state(kind string, name string, state sitewhereiov1alpha4.BootstrapState) {
+	key := kind + "/" + name
+	if previous, ok := w.states[key]; ok && previous == state {
+		return
+	}
+	w.states[key] = state
+	if w.bootstrapProgress != nil {
+		w.bootstrapProgress(kind, name, state)
+	}
+}
+
+// workload records the status of a Deployment, reporting it if changed
+func (w *bootstrapWaiter) workload(workload status.SiteWhereStatus) {
+	key := workload.Kind + "/" + workload.Name
+	if previous, ok := w.workloads[key]; ok && previous.Status == workload.Status && previous.Detail == workload.Detail {
+		return
+	}
+	w.workloads[key] = workload
+	if w.progress != nil {
+		w.progress(workload)
+	}
+}
+
+// pending describes the components not bootstrapped or not ready
+func (w *bootstrapWaiter) pending() string {
+	var result []string
+	for key, state := range w.states {
+		if state != sitewhereiov1alpha4.Bootstrapped {
+			result = append(result, fmt.Sprintf("%s %s", key, valueOrUnknown(string(state))))
+		}
+	}
+	for key, workload := range w.workloads {
+		if workload.Status != status.Installed {
+			result = append(result, fmt.Sprintf("%s %s", key, workload.Detail))
+		}
+	}
+	sort.Strings(result)
+	return strings.Join(result, ", ")
+}
+
+// wait calls poll until it reports that every component is bootstrapped,
+// poll fails or the timeout expires
+func (w *bootstrapWaiter) wait(ctx context.Context, timeout time.Duration, poll func(ctx context.Context) (bool, error)) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		done, err := poll(ctx)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out after %s waiting for: %s", timeout, w.pending())
+		case <-time.After(w.interval):
+		}
+	}
+}
+
+// bootstrapFailed returns an error if a state is BootstrapFailed
+func bootstrapFailed(kind string, name string, state sitewhereiov1alpha4.BootstrapState) error {
+	if state == sitewhereiov1alpha4.BootstrapFailed {
+		return fmt.Errorf("bootstrap of %s %s failed", kind, name)
+	}
+	return nil
+}
+
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "Unknown"
+	}
+	return value
+}
+
+// pollInstanceBootstrap checks the bootstrap state of tenant management and
+// user management of the instance, and the Deployments of its microservices
+func (w *bootstrapWaiter) pollInstanceBootstrap(ctx context.Context, client ctlcli.Client, clientset kubernetes.Interface,
+	instanceName string, namespace string) (bool, error) {
+	var swInstance sitewhereiov1alpha4.SiteWhereInstance
+	if err := client.Get(ctx, ctlcli.ObjectKey{Name: instanceName}, &swInstance); err != nil {
+		return false, err
+	}
+	var done = true
+	for _, component := range []struct {
+		name  string
+		state sitewhereiov1alpha4.BootstrapState
+	}{
+		{name: tenantManagementComponent, state: swInstance.Status.TenantManagementBootstrapState},
+		{name: userManagementComponent, state: swInstance.Status.UserManagementBootstrapState},
+	} {
+		w.state(sitewhereiov1alpha4.SiteWhereInstanceKind, component.name, component.state)
+		if err := bootstrapFailed(sitewhereiov1alpha4.SiteWhereInstanceKind, component.name, component.state); err != nil {
+			return false, err
+		}
+		if component.state != sitewhereiov1alpha4.Bootstrapped {
+			done = false
+		}
+	}
+	var swMicroserviceList sitewhereiov1alpha4.SiteWhereMicroserviceList
+	if err := client.List(ctx, &swMicroserviceList, ctlcli.InNamespace(namespace)); err != nil {
+		return false, err
+	}
+	if len(swMicroserviceList.Items) == 0 {
+		return false, nil
+	}
+	available, err := w.pollDeployments(ctx, clientset, namespace, swMicroserviceList.Items)
+	if err != nil {
+		return false, err
+	}
+	return done && available, nil
+}
+
+// pollDeployments checks that the Deployments of the microservices are
+// available
+func (w *bootstrapWaiter) pollDeployments(ctx context.Context, clientset kubernetes.Interface, namespace string,
+	microservices []sitewhereiov1alpha4.SiteWhereMicroservice) (bool, error) {
+	var done = true
+	for _, swMicroservice := range microservices {
+		if swMicroservice.Status.Deployment == "" {
+			done = false
+			continue
+		}
+		deploy, err := clientset.AppsV1().Deployments(namespace).Get(ctx, swMicroservice.Status.Deployment, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		workload := deploymentStatus(deploy)
+		w.workload(workload)
+		if workload.Status != status.Installed {
+			done = false
+		}
+	}
+	return done, nil
+}
+
+// pollTenantBootstrap checks the bootstrap state of the tenant engines of the
+// tenant, one for each multitenant microservice of the instance, and the
+// Deployments of the microservices
+func (w *bootstrapWaiter) pollTenantBootstrap(ctx context.Context, client ctlcli.Client, clientset kubernetes.Interface,
+	tenantName string, namespace string) (bool, error) {
+	var swMicroserviceList sitewhereiov1alpha4.SiteWhereMicroserviceList
+	if err := client.List(ctx, &swMicroserviceList, ctlcli.InNamespace(namespace)); err != nil {
+		return false, err
+	}
+	available, err := w.pollDeployments(ctx, clientset, namespace, swMicroserviceList.Items)
+	if err != nil {
+		return false, err
+	}
+	var expected = 0
+	for _, swMicroservice := range swMicroserviceList.Items {
+		if swMicroservice.Spec.Multitenant {
+			expected++
+		}
+	}
+	var swTenantEngineList sitewhereiov1alpha4.SiteWhereTenantEngineList
+	if err := client.List(ctx, &swTenantEngineList, ctlcli.InNamespace(namespace), ctlcli.MatchingLabels{tenantLabel: tenantName}); err != nil {
+		return false, err
+	}
+	var done = available && len(swTenantEngineList.Items) >= expected
+	for _, swTenantEngine := range swTenantEngineList.Items {
+		state := swTenantEngine.Status.BootstrapState
+		w.state(sitewhereiov1alpha4.SiteWhereTenantEngineKind, swTenantEngine.GetName(), state)
+		if err := bootstrapFailed(sitewhereiov1alpha4.SiteWhereTenantEngineKind, swTenantEngine.GetName(), state); err != nil {
+			return false, err
+		}
+		if state != sitewhereiov1alpha4.Bootstrapped {
+			done = false
+		}
+	}
+	return done, nil
+}
@@ -0,0 +1,168 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	ctlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
+
+	"github.com/sitewhere/swctl/pkg/status"
+)
+
+func bootstrapInstance(tenantManagement sitewhereiov1alpha4.BootstrapState, userManagement sitewhereiov1alpha4.BootstrapState) *sitewhereiov1alpha4.SiteWhereInstance {
+	return &sitewhereiov1alpha4.SiteWhereInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "sitewhere"},
+		Status: sitewhereiov1alpha4.SiteWhereInstanceStatus{
+			TenantManagementBootstrapState: tenantManagement,
+			UserManagementBootstrapState:   userManagement,
+		},
+	}
+}
+
+func TestPollInstanceBootstrap(t *testing.T) {
+	t.Parallel()
+	replicas := int32(1)
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "sitewhere-device-management", Namespace: "sitewhere"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{AvailableReplicas: 1},
+	}
+	swMicroservice := rollingMicroservice("device-management")
+	swMicroservice.Status.Deployment = "sitewhere-device-management"
+	data := []struct {
+		name     string
+		instance *sitewhereiov1alpha4.SiteWhereInstance
+		done     bool
+		err      bool
+	}{
+		{name: "bootstrapped", instance: bootstrapInstance(sitewhereiov1alpha4.Bootstrapped, sitewhereiov1alpha4.Bootstrapped), done: true},
+		{name: "not-bootstrapped", instance: bootstrapInstance(sitewhereiov1alpha4.Bootstrapped, sitewhereiov1alpha4.NotBootstrapped)},
+		{name: "bootstrap-failed", instance: bootstrapInstance(sitewhereiov1alpha4.BootstrapFailed, sitewhereiov1alpha4.Bootstrapped), err: true},
+	}
+	for _, single := range data {
+		t.Run(single.name, func(single struct {
+			name     string
+			instance *sitewhereiov1alpha4.SiteWhereInstance
+			done     bool
+			err      bool
+		}) func(t *testing.T) {
+			return func(t *testing.T) {
+				client := ctlfake.NewFakeClientWithScheme(scheme, single.instance, swMicroservice.DeepCopy())
+				clientset := fake.NewSimpleClientset(deploy)
+				var reported []string
+				waiter := newBootstrapWaiter(time.Millisecond, nil, func(kind string, name string, state sitewhereiov1alpha4.BootstrapState) {
+					reported = append(reported, name)
+				})
+				done, err := waiter.pollInstanceBootstrap(context.TODO(), client, clientset, "sitewhere", "sitewhere")
+				if single.err {
+					if err == nil {
+						t.Fatalf("expected error")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf(err.Error())
+				}
+				if done != single.done {
+					t.Fatalf("expected done %t, got %t", single.done, done)
+				}
+				if len(reported) != 2 {
+					t.Fatalf("expected tenant and user management states, got %v", reported)
+				}
+			}
+		}(single))
+	}
+}
+
+func TestPollTenantBootstrap(t *testing.T) {
+	swMicroservice := rollingMicroservice("device-management")
+	swMicroservice.Spec.Multitenant = true
+	tenantEngine := &sitewhereiov1alpha4.SiteWhereTenantEngine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "acme-device-management",
+			Namespace: "sitewhere",
+			Labels:    map[string]string{tenantLabel: "acme"},
+		},
+		Status: sitewhereiov1alpha4.SiteWhereTenantEngineStatus{BootstrapState: sitewhereiov1alpha4.Bootstrapping},
+	}
+	replicas := int32(1)
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "device-management", Namespace: "sitewhere"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{AvailableReplicas: 1},
+	}
+	client := ctlfake.NewFakeClientWithScheme(scheme, swMicroservice.DeepCopy(), tenantEngine)
+	clientset := fake.NewSimpleClientset(deploy)
+	waiter := newBootstrapWaiter(time.Millisecond, nil, nil)
+	err := waiter.wait(context.TODO(), 20*time.Millisecond, func(ctx context.Context) (bool, error) {
+		return waiter.pollTenantBootstrap(ctx, client, clientset, "acme", "sitewhere")
+	})
+	if err == nil || !strings.Contains(err.Error(), "acme-device-management Bootstrapping") {
+		t.Fatalf("expected timeout waiting for acme-device-management, got %v", err)
+	}
+	done, err := waiter.pollTenantBootstrap(context.TODO(), client, clientset, "other", "sitewhere")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if done {
+		t.Fatalf("expected tenant without tenant engines not to be bootstrapped")
+	}
+}
+
+func TestPollTenantBootstrapDeployments(t *testing.T) {
+	swMicroservice := rollingMicroservice("device-management")
+	swMicroservice.Spec.Multitenant = true
+	tenantEngine := &sitewhereiov1alpha4.SiteWhereTenantEngine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "acme-device-management",
+			Namespace: "sitewhere",
+			Labels:    map[string]string{tenantLabel: "acme"},
+		},
+		Status: sitewhereiov1alpha4.SiteWhereTenantEngineStatus{BootstrapState: sitewhereiov1alpha4.Bootstrapped},
+	}
+	replicas := int32(1)
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "device-management", Namespace: "sitewhere"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{UnavailableReplicas: 1},
+	}
+	client := ctlfake.NewFakeClientWithScheme(scheme, swMicroservice.DeepCopy(), tenantEngine)
+	clientset := fake.NewSimpleClientset(deploy)
+	var workloads []string
+	waiter := newBootstrapWaiter(time.Millisecond, func(component status.SiteWhereStatus) {
+		workloads = append(workloads, component.Name)
+	}, nil)
+	done, err := waiter.pollTenantBootstrap(context.TODO(), client, clientset, "acme", "sitewhere")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if done {
+		t.Fatalf("expected tenant not to be bootstrapped while its microservices are not available")
+	}
+	if len(workloads) != 1 {
+		t.Fatalf("expected the deployment progress to be reported, got %v", workloads)
+	}
+}
@@ -0,0 +1,176 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/sitewhere/swctl/pkg/cli"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/downloader"
+	"helm.sh/helm/v3/pkg/getter"
+)
+
+// ChartSource are the options used to locate SiteWhere Infrastructure Helm Chart
+type ChartSource struct {
+	// ChartPath is the path of a local chart archive (.tgz) or unpacked chart directory
+	ChartPath string
+	// SkipRepoUpdate if true, the chart repository is not added nor updated
+	SkipRepoUpdate bool
+}
+
+func newChartSource() ChartSource {
+	return ChartSource{
+		ChartPath:      "",
+		SkipRepoUpdate: false,
+	}
+}
+
+// prepareRepository adds and updates SiteWhere chart repository,
+// unless a local chart is used or the repository update is skipped
+func (c *ChartSource) prepareRepository(settings *cli.EnvSettings) error {
+	if c.ChartPath != "" || c.SkipRepoUpdate {
+		return nil
+	}
+	err := addSiteWhereRepository(settings)
+	if err != nil {
+		return err
+	}
+	return updateSiteWhereRepository(settings)
+}
+
+// newReleaseConfiguration creates a Helm action configuration bound
+// to the namespace of SiteWhere Infrastructure release
+func newReleaseConfiguration(settings *cli.EnvSettings, verbose bool) (*action.Configuration, error) {
+	actionConfig := new(action.Configuration)
+	var logConf action.DebugLog
+	if verbose {
+		logConf = log.Printf
+	} else {
+		logConf = Discardf
+	}
+	if err := actionConfig.Init(settings.RESTClientGetter(), settings.SystemNamespace, os.Getenv("HELM_DRIVER"), logConf); err != nil {
+		return nil, err
+	}
+	return actionConfig, nil
+}
+
+// loadSiteWhereChart locates and loads SiteWhere Infrastructure Helm Chart,
+// making sure all its dependencies are present in /charts. If chartPath
+// is set, the chart is loaded from that local archive or directory.
+func loadSiteWhereChart(cpo *action.ChartPathOptions, chartPath string, dependencyUpdate bool, settings *cli.EnvSettings) (*chart.Chart, error) {
+	var err error
+	var cp string
+	if chartPath != "" {
+		cp, err = localChartPath(chartPath)
+		if err != nil {
+			return nil, err
+		}
+	} else if cached, ok := cachedChartPath(cpo.Version, settings); ok {
+		cp = cached
+	} else {
+		cp, err = cpo.LocateChart(fmt.Sprintf("%s/%s", settings.Repository.Name, settings.ChartName), settings.EnvSettings)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	chartRequested, err := loader.Load(cp)
+	if err != nil {
+		return nil, err
+	}
+
+	validInstallableChart, err := isChartInstallable(chartRequested)
+	if !validInstallableChart {
+		return nil, err
+	}
+
+	if req := chartRequested.Metadata.Dependencies; req != nil {
+		// If CheckDependencies returns an error, we have unfulfilled dependencies.
+		// As of Helm 2.4.0, this is treated as a stopping condition:
+		// https://github.com/helm/helm/issues/2209
+		if err := action.CheckDependencies(chartRequested, req); err != nil {
+			if dependencyUpdate {
+				man := &downloader.Manager{
+					Out:              os.Stdout,
+					ChartPath:        cp,
+					Keyring:          cpo.Keyring,
+					SkipUpdate:       false,
+					Getters:          getter.All(settings.EnvSettings),
+					RepositoryConfig: settings.RepositoryConfig,
+					RepositoryCache:  settings.RepositoryCache,
+				}
+				if err := man.Update(); err != nil {
+					return nil, err
+				}
+				// Reload the chart with the updated Chart.lock file.
+				if chartRequested, err = loader.Load(cp); err != nil {
+					return nil, errors.Wrap(err, "failed reloading chart after repo update")
+				}
+			} else if chartPath != "" {
+				return nil, errors.Wrapf(err, "dependencies of %s must be vendored in its charts/ directory", chartPath)
+			} else {
+				return nil, err
+			}
+		}
+	}
+	return chartRequested, nil
+}
+
+// localChartPath returns the absolute path of a local chart archive or directory
+func localChartPath(chartPath string) (string, error) {
+	abs, err := filepath.Abs(chartPath)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(abs); err != nil {
+		if os.IsNotExist(err) {
+			return "", errors.Errorf("chart path %q not found", chartPath)
+		}
+		return "", err
+	}
+	return abs, nil
+}
+
+// cachedChartPath returns the path of SiteWhere Infrastructure Helm Chart archive
+// in the local repository cache and whether it was already downloaded
+func cachedChartPath(version string, settings *cli.EnvSettings) (string, bool) {
+	if version == "" {
+		return "", false
+	}
+	cp := filepath.Join(settings.RepositoryCache, fmt.Sprintf("%s-%s.tgz", settings.ChartName, version))
+	if _, err := os.Stat(cp); err != nil {
+		return "", false
+	}
+	return cp, true
+}
+
+func isChartInstallable(ch *chart.Chart) (bool, error) {
+	switch ch.Metadata.Type {
+	case "", "application":
+		return true, nil
+	}
+	return false, errors.Errorf("%s charts are not installable", ch.Metadata.Type)
+}
@@ -17,51 +17,285 @@
 package action
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sitewhere/swctl/pkg/cli"
 	"github.com/sitewhere/swctl/pkg/install"
+	"github.com/sitewhere/swctl/pkg/resources"
+	"github.com/sitewhere/swctl/pkg/status"
 
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+const (
+	crdKind         = "CustomResourceDefinition"
+	namespaceKind   = "Namespace"
+	deploymentKind  = "Deployment"
+	statefulSetKind = "StatefulSet"
+	helmReleaseKind = "HelmRelease"
+	// infrastructureKind is the kind of an infrastructure component
+	infrastructureKind = "Infrastructure"
 )
 
+// sitewhereCRDs are the Custom Resource Definitions required by SiteWhere
+var sitewhereCRDs = []string{
+	"instances.sitewhere.io",
+	"microservices.sitewhere.io",
+	"tenants.sitewhere.io",
+	"tenantengines.sitewhere.io",
+}
+
 // CheckInstall is the action for check SiteWhere installation
 type CheckInstall struct {
 	cfg *action.Configuration
 
+	settings *cli.EnvSettings
+
 	// Use verbose mode
 	Verbose bool
 }
 
 // NewCheckInstall constructs a new *Install
-func NewCheckInstall(cfg *action.Configuration) *CheckInstall {
+func NewCheckInstall(cfg *action.Configuration, settings *cli.EnvSettings) *CheckInstall {
 	return &CheckInstall{
-		cfg:     cfg,
-		Verbose: false,
+		cfg:      cfg,
+		settings: settings,
+		Verbose:  false,
 	}
 }
 
-// Run executes the list command, returning a set of matches.
+// Run executes the check install command, returning the status of each component.
 func (i *CheckInstall) Run() (*install.SiteWhereInstall, error) {
 	if err := i.cfg.KubeClient.IsReachable(); err != nil {
 		return nil, err
 	}
-	// clientSet, err := i.cfg.KubernetesClientSet()
-	// if err != nil {
-	// 	return nil, err
-	// }
-	// extensionsClients, err := i.cfg.KubernetesAPIExtensionClientSet()
-	// if err != nil {
-	// 	return nil, err
-	// }
-	// config, err := i.cfg.RESTClientGetter.ToRESTConfig()
-	// if err != nil {
-	// 	return nil, err
-	// }
-	// Install Custom Resource Definitions
-	// _, err = check.SiteWhereCRDs(i.StatikFS, clientSet, extensionsClients, config)
-	// if err != nil {
-	// 	return nil, err
-	// }
+	clientSet, err := i.cfg.KubernetesClientSet()
+	if err != nil {
+		return nil, err
+	}
+	extensionsClient, err := KubernetesAPIExtensionClientSet(i.cfg)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.TODO()
+
+	crdStatuses, err := checkCRDs(ctx, extensionsClient)
+	if err != nil {
+		return nil, err
+	}
+	workloadStatuses, err := checkWorkloads(ctx, clientSet, i.settings.SystemNamespace, true)
+	if err != nil {
+		return nil, err
+	}
+	releaseStatus, err := i.checkRelease()
+	if err != nil {
+		return nil, err
+	}
+
+	var components []status.SiteWhereStatus
+	components = append(components, crdStatuses...)
+	components = append(components, workloadStatuses...)
+	components = append(components, *releaseStatus)
 
 	return &install.SiteWhereInstall{
-		// CDRStatues: crdStatues,
+		Release:    i.settings.ReleaseName,
+		Namespace:  i.settings.SystemNamespace,
+		Components: components,
 	}, nil
 }
+
+func (i *CheckInstall) checkRelease() (*status.SiteWhereStatus, error) {
+	result := &status.SiteWhereStatus{
+		Name: i.settings.ReleaseName,
+		Kind: helmReleaseKind,
+	}
+	actionConfig, err := newReleaseConfiguration(i.settings, i.Verbose)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := action.NewStatus(actionConfig).Run(i.settings.ReleaseName)
+	if err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			result.Status = status.Uninstalled
+			return result, nil
+		}
+		return nil, err
+	}
+	result.Detail = fmt.Sprintf("revision %d, chart %s, %s", rel.Version, rel.Chart.Metadata.Version, rel.Info.Status)
+	if rel.Info.Status == release.StatusDeployed {
+		result.Status = status.Installed
+	} else {
+		result.Status = status.NotReady
+	}
+	return result, nil
+}
+
+// checkCRDs checks the required SiteWhere CRDs and any other CRD of
+// a sitewhere.io group found in the cluster
+func checkCRDs(ctx context.Context, extensionsClient clientset.Interface) ([]status.SiteWhereStatus, error) {
+	crdList, err := extensionsClient.ApiextensionsV1().CustomResourceDefinitions().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var found = map[string]apiextv1.CustomResourceDefinition{}
+	var names = append([]string{}, sitewhereCRDs...)
+	for _, crd := range crdList.Items {
+		if !isSiteWhereCRDGroup(crd.Spec.Group) {
+			continue
+		}
+		if !containsString(sitewhereCRDs, crd.GetName()) {
+			names = append(names, crd.GetName())
+		}
+		found[crd.GetName()] = crd
+	}
+	sort.Strings(names[len(sitewhereCRDs):])
+
+	var result []status.SiteWhereStatus
+	for _, name := range names {
+		crdStatus := status.SiteWhereStatus{
+			Name: name,
+			Kind: crdKind,
+		}
+		crd, ok := found[name]
+		if !ok {
+			crdStatus.Status = status.Uninstalled
+		} else if crdEstablished(&crd) {
+			crdStatus.Status = status.Installed
+			crdStatus.Detail = "Established"
+		} else {
+			crdStatus.Status = status.NotReady
+			crdStatus.Detail = "Not Established"
+		}
+		result = append(result, crdStatus)
+	}
+	return result, nil
+}
+
+func crdEstablished(crd *apiextv1.CustomResourceDefinition) bool {
+	for _, cond := range crd.Status.Conditions {
+		if cond.Type == apiextv1.Established {
+			return cond.Status == apiextv1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// isSiteWhereCRDGroup returns true for the sitewhere.io group and its subgroups
+func isSiteWhereCRDGroup(group string) bool {
+	return group == sitewhereCRDGroup || strings.HasSuffix(group, "."+sitewhereCRDGroup)
+}
+
+// checkWorkloads checks the operator Deployment and every infrastructure
+// Deployment and StatefulSet in the namespace. If withOperator is false, the
+// operator Deployment is neither required nor reported.
+func checkWorkloads(ctx context.Context, clientset kubernetes.Interface, namespace string, withOperator bool) ([]status.SiteWhereStatus, error) {
+	exists, err := resources.CheckIfExistsNamespace(namespace, clientset)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return []status.SiteWhereStatus{
+			{
+				Name:   namespace,
+				Kind:   namespaceKind,
+				Status: status.Uninstalled,
+			},
+		}, nil
+	}
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var operator *status.SiteWhereStatus
+	var result []status.SiteWhereStatus
+	for _, deploy := range deployments.Items {
+		workloadStatus := deploymentStatus(&deploy)
+		if isOperatorDeployment(&deploy) {
+			if withOperator && operator == nil {
+				operator = &workloadStatus
+			}
+			continue
+		}
+		result = append(result, workloadStatus)
+	}
+	for _, sts := range statefulSets.Items {
+		result = append(result, statefulSetStatus(&sts))
+	}
+	if !withOperator {
+		return result, nil
+	}
+	if operator == nil {
+		operator = &status.SiteWhereStatus{
+			Name:   sitewhereOperatorName,
+			Kind:   deploymentKind,
+			Status: status.Uninstalled,
+		}
+	}
+	return append([]status.SiteWhereStatus{*operator}, result...), nil
+}
+
+// isOperatorDeployment returns true for the SiteWhere Operator Deployment,
+// named sitewhere-operator, prefixed by the release name or labeled with it
+func isOperatorDeployment(deploy *appsv1.Deployment) bool {
+	name := deploy.GetName()
+	return name == sitewhereOperatorName ||
+		strings.HasSuffix(name, "-"+sitewhereOperatorName) ||
+		deploy.GetLabels()[sitewhereOperatorLabel] == sitewhereOperatorName
+}
+
+func deploymentStatus(deploy *appsv1.Deployment) status.SiteWhereStatus {
+	var desired int32 = 1
+	if deploy.Spec.Replicas != nil {
+		desired = *deploy.Spec.Replicas
+	}
+	return workloadStatus(deploy.GetName(), deploymentKind, desired, deploy.Status.AvailableReplicas)
+}
+
+func statefulSetStatus(sts *appsv1.StatefulSet) status.SiteWhereStatus {
+	var desired int32 = 1
+	if sts.Spec.Replicas != nil {
+		desired = *sts.Spec.Replicas
+	}
+	return workloadStatus(sts.GetName(), statefulSetKind, desired, sts.Status.ReadyReplicas)
+}
+
+func workloadStatus(name string, kind string, desired int32, ready int32) status.SiteWhereStatus {
+	result := status.SiteWhereStatus{
+		Name:   name,
+		Kind:   kind,
+		Detail: fmt.Sprintf("%d/%d ready", ready, desired),
+	}
+	if ready >= desired {
+		result.Status = status.Installed
+	} else {
+		result.Status = status.NotReady
+	}
+	return result
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsFake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sitewhere/swctl/pkg/status"
+)
+
+func TestCheckCRDs(t *testing.T) {
+	client := apiextensionsFake.NewSimpleClientset(
+		&apiextv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "instances.sitewhere.io"},
+			Spec:       apiextv1.CustomResourceDefinitionSpec{Group: "sitewhere.io"},
+			Status: apiextv1.CustomResourceDefinitionStatus{
+				Conditions: []apiextv1.CustomResourceDefinitionCondition{
+					{Type: apiextv1.Established, Status: apiextv1.ConditionTrue},
+				},
+			},
+		},
+		&apiextv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "microservices.sitewhere.io"},
+			Spec:       apiextv1.CustomResourceDefinitionSpec{Group: "sitewhere.io"},
+		},
+		&apiextv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "instanceconfigurations.templates.sitewhere.io"},
+			Spec:       apiextv1.CustomResourceDefinitionSpec{Group: "templates.sitewhere.io"},
+		},
+		&apiextv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "other.example.com"},
+			Spec:       apiextv1.CustomResourceDefinitionSpec{Group: "example.com"},
+		},
+		&apiextv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "others.notsitewhere.io"},
+			Spec:       apiextv1.CustomResourceDefinitionSpec{Group: "notsitewhere.io"},
+		},
+	)
+	result, err := checkCRDs(context.TODO(), client)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	expected := map[string]status.Status{
+		"instances.sitewhere.io":                        status.Installed,
+		"microservices.sitewhere.io":                    status.NotReady,
+		"tenants.sitewhere.io":                          status.Uninstalled,
+		"tenantengines.sitewhere.io":                    status.Uninstalled,
+		"instanceconfigurations.templates.sitewhere.io": status.NotReady,
+	}
+	if len(expected) != len(result) {
+		t.Fatalf("expected %d crds, got %d crds", len(expected), len(result))
+	}
+	for _, r := range result {
+		if expected[r.Name] != r.Status {
+			t.Fatalf("expected %s status: %s got status: %s", r.Name, expected[r.Name], r.Status)
+		}
+	}
+}
+
+func TestCheckWorkloads(t *testing.T) {
+	var replicas int32 = 2
+	client := fake.NewSimpleClientset(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "sitewhere-system"}},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "sitewhere-keycloak", Namespace: "sitewhere-system"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{AvailableReplicas: 1},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "strimzi-cluster-operator", Namespace: "sitewhere-system"},
+			Status:     appsv1.DeploymentStatus{AvailableReplicas: 1},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "sitewhere-postgresql", Namespace: "sitewhere-system"},
+			Status:     appsv1.StatefulSetStatus{ReadyReplicas: 1},
+		},
+	)
+	result, err := checkWorkloads(context.TODO(), client, "sitewhere-system", true)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(result) != 4 {
+		t.Fatalf("expected 4 workloads, got %d workloads", len(result))
+	}
+	if result[0].Name != sitewhereOperatorName || result[0].Status != status.Uninstalled {
+		t.Fatalf("expected missing operator, got %s %s", result[0].Name, result[0].Status)
+	}
+	if result[1].Status != status.NotReady {
+		t.Fatalf("expected keycloak not ready, got %s", result[1].Status)
+	}
+	if result[2].Name != "strimzi-cluster-operator" || result[2].Status != status.Installed {
+		t.Fatalf("expected strimzi operator installed, got %s %s", result[2].Name, result[2].Status)
+	}
+	if result[3].Status != status.Installed {
+		t.Fatalf("expected postgresql installed, got %s", result[3].Status)
+	}
+
+	skipOperator, err := checkWorkloads(context.TODO(), client, "sitewhere-system", false)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(skipOperator) != 3 || skipOperator[0].Name == sitewhereOperatorName {
+		t.Fatalf("expected workloads without the operator, got %v", skipOperator)
+	}
+
+	missing, err := checkWorkloads(context.TODO(), fake.NewSimpleClientset(), "sitewhere-system", true)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(missing) != 1 || missing[0].Kind != namespaceKind {
+		t.Fatalf("expected missing namespace, got %v", missing)
+	}
+}
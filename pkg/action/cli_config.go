@@ -0,0 +1,104 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/sitewhere/swctl/pkg/cli"
+	"github.com/sitewhere/swctl/pkg/config"
+	"github.com/sitewhere/swctl/pkg/install"
+)
+
+// externalServices are the infrastructure services that can be external
+var externalServices = []string{"kafka", "postgresql", "influxdb", "redis", "keycloak"}
+
+// ExternalServices returns the names of the infrastructure services that can be external
+func ExternalServices() []string {
+	return append([]string{}, externalServices...)
+}
+
+// SetInfrastructure is the action for storing an external infrastructure
+// service in swctl configuration file
+type SetInfrastructure struct {
+	settings *cli.EnvSettings
+
+	// Service is the name of the infrastructure service
+	Service string
+	// Endpoint is the address and credentials of the service
+	Endpoint config.ServiceEndpoint
+	// Unset removes the service, so the one installed by swctl is used
+	Unset bool
+}
+
+// NewSetInfrastructure constructs a new *SetInfrastructure
+func NewSetInfrastructure(settings *cli.EnvSettings) *SetInfrastructure {
+	return &SetInfrastructure{
+		settings: settings,
+		Service:  "",
+		Endpoint: config.ServiceEndpoint{},
+		Unset:    false,
+	}
+}
+
+// Run stores the service in the configuration file and returns the external infrastructure
+func (s *SetInfrastructure) Run() (*config.ExternalInfrastructure, error) {
+	conf, err := config.LoadCLIConfiguration(s.settings.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err := externalServiceEndpoint(&conf.Infrastructure, s.Service)
+	if err != nil {
+		return nil, err
+	}
+	if s.Unset {
+		*endpoint = config.ServiceEndpoint{}
+	} else {
+		if s.Endpoint.Host == "" {
+			return nil, errors.New("the host of the service is required")
+		}
+		*endpoint = s.Endpoint
+	}
+	if err = config.SaveCLIConfiguration(s.settings.ConfigFile, conf); err != nil {
+		return nil, err
+	}
+	s.settings.Infrastructure = conf.Infrastructure
+	return &conf.Infrastructure, nil
+}
+
+// externalServiceEndpoint returns the endpoint of a service of the external infrastructure
+func externalServiceEndpoint(infra *config.ExternalInfrastructure, service string) (*config.ServiceEndpoint, error) {
+	component, err := install.ParseComponent(service)
+	if err != nil {
+		return nil, err
+	}
+	switch component {
+	case install.Strimzi:
+		return &infra.Kafka, nil
+	case install.PostgreSQL:
+		return &infra.PostgreSQL, nil
+	case install.InfluxDB:
+		return &infra.InfluxDB, nil
+	case install.Redis:
+		return &infra.Redis, nil
+	case install.Keycloak:
+		return &infra.Keycloak, nil
+	}
+	return nil, errors.Errorf("%s cannot be an external service, valid services are: %s", component, strings.Join(externalServices, ", "))
+}
@@ -0,0 +1,68 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sitewhere/swctl/pkg/cli"
+	"github.com/sitewhere/swctl/pkg/config"
+)
+
+func TestSetInfrastructure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "swctl-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	settings := &cli.EnvSettings{ConfigFile: filepath.Join(dir, "config.yaml")}
+
+	set := NewSetInfrastructure(settings)
+	set.Service = "kafka"
+	set.Endpoint = config.ServiceEndpoint{Host: "kafka.example.com", Port: 9092}
+	if _, err = set.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conf, err := config.LoadCLIConfiguration(settings.ConfigFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.Infrastructure.Kafka.Host != "kafka.example.com" || conf.Infrastructure.Kafka.Port != 9092 {
+		t.Fatalf("expected kafka to be stored, got %+v", conf.Infrastructure.Kafka)
+	}
+
+	unset := NewSetInfrastructure(settings)
+	unset.Service = "strimzi"
+	unset.Unset = true
+	infra, err := unset.Run()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if infra.Kafka.Host != "" || settings.Infrastructure.Kafka.Host != "" {
+		t.Fatalf("expected kafka to be removed, got %+v", infra.Kafka)
+	}
+
+	invalid := NewSetInfrastructure(settings)
+	invalid.Service = "mosquitto"
+	invalid.Endpoint = config.ServiceEndpoint{Host: "mqtt.example.com"}
+	if _, err = invalid.Run(); err == nil {
+		t.Fatal("expected error for a service that cannot be external")
+	}
+}
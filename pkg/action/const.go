@@ -17,14 +17,13 @@
 package action
 
 const (
-	sitewhereRepoName        = "sitewhere"
-	sitewhereRepoURL         = "https://sitewhere.io/helm-charts"
-	sitewhereChartName       = "sitewhere-infrastructure"
-	sitewhereSystemNamespace = "sitewhere-system"
-	sitewhereReleaseName     = "sitewhere"
+	sitewhereOperatorName = "sitewhere-operator"
+	sitewhereCRDGroup     = "sitewhere.io"
+	// sitewhereOperatorLabel is the label with the name of the operator
+	sitewhereOperatorLabel = "app.kubernetes.io/name"
 )
 
 const (
 	// ErrIstioNotInstalled is the error when istio is not installed
-	ErrIstioNotInstalled = "Istio is not intalled, install istio with `istioctl install` and try again"
+	ErrIstioNotInstalled = "Istio is not installed, install istio with `istioctl install` and try again"
 )
@@ -19,16 +19,21 @@ package action
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/sitewhere/swctl/pkg/cli"
 	"github.com/sitewhere/swctl/pkg/config"
 	"github.com/sitewhere/swctl/pkg/install/profile"
 	"github.com/sitewhere/swctl/pkg/instance"
+	"github.com/sitewhere/swctl/pkg/preflight"
 
 	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
 
@@ -38,6 +43,9 @@ import (
 // CreateInstance is the action for creating a SiteWhere instance
 type CreateInstance struct {
 	cfg *action.Configuration
+
+	settings *cli.EnvSettings
+
 	// Name of the instance
 	InstanceName string
 	// Name of the tenant
@@ -46,6 +54,8 @@ type CreateInstance struct {
 	Namespace string
 	// Minimal use minimal profile. Initialize only essential microservices.
 	Minimal bool
+	// Profile is the name of the profile of the instance
+	Profile string
 	// Number of replicas
 	Replicas int32
 	// Registry is the docker registry of the microservices images
@@ -54,10 +64,26 @@ type CreateInstance struct {
 	Tag string
 	// Use debug mode
 	Debug bool
+	// DebugMicroservices are the functional areas of the microservices in debug mode
+	DebugMicroservices []string
+	// SkipPreflight skips the pre-flight checks
+	SkipPreflight bool
 	// Configuration Template
 	ConfigurationTemplate string
 	// Dataset template
 	DatasetTemplate string
+	// Wait for the instance to bootstrap
+	Wait bool
+	// Timeout to wait for the instance to bootstrap
+	Timeout time.Duration
+	// Progress is called when the status of a microservice Deployment changes
+	Progress ProgressFunc
+	// BootstrapProgress is called when a bootstrap state of the instance changes
+	BootstrapProgress BootstrapProgressFunc
+	// Out receives the pre-flight warnings
+	Out io.Writer
+
+	pollInterval time.Duration
 }
 
 type namespaceAndResourcesResult struct {
@@ -88,19 +114,29 @@ const defaultConfigurationTemplate = "default"
 const defaultDatasetTemplate = "default"
 
 // NewCreateInstance constructs a new *Install
-func NewCreateInstance(cfg *action.Configuration) *CreateInstance {
+func NewCreateInstance(cfg *action.Configuration, settings *cli.EnvSettings) *CreateInstance {
 	return &CreateInstance{
 		cfg:                   cfg,
+		settings:              settings,
 		InstanceName:          "",
 		TenantName:            "default",
 		Namespace:             "",
 		Minimal:               false,
+		Profile:               "",
 		Replicas:              1,
 		Tag:                   dockerImageDefaultTag,
 		Registry:              sitewhereiov1alpha4.DefaultDockerSpec.Registry,
 		Debug:                 false,
+		DebugMicroservices:    nil,
+		SkipPreflight:         false,
 		ConfigurationTemplate: defaultConfigurationTemplate,
 		DatasetTemplate:       defaultDatasetTemplate,
+		Wait:                  false,
+		Timeout:               defaultBootstrapTimeout,
+		Progress:              nil,
+		BootstrapProgress:     nil,
+		Out:                   os.Stdout,
+		pollInterval:          installPollInterval,
 	}
 }
 
@@ -109,7 +145,74 @@ func (i *CreateInstance) Run() (*instance.CreateSiteWhereInstance, error) {
 	if err := i.cfg.KubeClient.IsReachable(); err != nil {
 		return nil, err
 	}
-	var prof profile.SiteWhereProfile = profile.Default
+	i.setDefaults()
+	i.ignoreNamespace()
+	prof, err := i.loadProfile()
+	if err != nil {
+		return nil, err
+	}
+	if i.settings.Infrastructure.Configured() {
+		if path, outdated := config.OutdatedConfigurationTemplate(prof); outdated {
+			fmt.Fprintf(i.Out, "Warning: %s was saved by an older swctl and ignores the external infrastructure, delete it or include the %s template in the env of the microservices\n",
+				path, config.InfrastructureTemplateName)
+		}
+	}
+	if !i.SkipPreflight {
+		report, err := runPreflight(i.cfg, preflight.InstanceChecks(), "", prof.Metadata.Base == profile.Minimal)
+		if err != nil {
+			return nil, err
+		}
+		writePreflightWarnings(i.Out, report)
+		if err = report.Err(); err != nil {
+			return nil, err
+		}
+	}
+	result, err := i.createSiteWhereInstance(prof)
+	if err != nil || !i.Wait {
+		return result, err
+	}
+	if err := i.waitForBootstrap(); err != nil {
+		return result, err
+	}
+	result.Bootstrapped = true
+	return result, nil
+}
+
+// waitForBootstrap waits for tenant management, user management and the
+// Deployments of the microservices of the instance
+func (i *CreateInstance) waitForBootstrap() error {
+	client, err := ControllerClient(i.cfg)
+	if err != nil {
+		return err
+	}
+	clientset, err := i.cfg.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+	waiter := newBootstrapWaiter(i.pollInterval, i.Progress, i.BootstrapProgress)
+	return waiter.wait(context.TODO(), i.Timeout, func(ctx context.Context) (bool, error) {
+		// The namespace is resolved again until the operator creates it
+		_, namespace, err := getInstance(ctx, i.cfg, client, i.InstanceName)
+		if err != nil {
+			return false, err
+		}
+		return waiter.pollInstanceBootstrap(ctx, client, clientset, i.InstanceName, namespace)
+	})
+}
+
+// ignoreNamespace warns that the deprecated namespace setting is ignored.
+// The operator always creates the namespace of the instance with the
+// instance name.
+func (i *CreateInstance) ignoreNamespace() {
+	if i.Namespace != i.InstanceName {
+		fmt.Fprintf(i.Out, "Warning: the namespace %s is deprecated and ignored, the operator creates the namespace %s with the instance name\n",
+			i.Namespace, i.InstanceName)
+		i.Namespace = i.InstanceName
+	}
+}
+
+// setDefaults sets the defaults of the settings left empty
+func (i *CreateInstance) setDefaults() {
 	if i.Namespace == "" {
 		i.Namespace = i.InstanceName
 	}
@@ -119,25 +222,49 @@ func (i *CreateInstance) Run() (*instance.CreateSiteWhereInstance, error) {
 	if i.ConfigurationTemplate == "" {
 		i.ConfigurationTemplate = defaultConfigurationTemplate
 	}
+	if i.DatasetTemplate == "" {
+		i.DatasetTemplate = defaultDatasetTemplate
+	}
+}
+
+// loadProfile loads the profile of the instance, minimal if Minimal is set,
+// and applies its configuration and dataset templates unless they were set.
+func (i *CreateInstance) loadProfile() (*config.Profile, error) {
+	var name = string(profile.Default)
 	if i.Minimal {
-		prof = profile.Minimal
-		i.ConfigurationTemplate = "minimal"
+		name = string(profile.Minimal)
+	}
+	if i.Profile != "" {
+		name = i.Profile
+	}
+	prof, err := config.LoadProfile(config.GetProfilesPath(), name)
+	if err != nil {
+		return nil, err
+	}
+	if prof.Metadata.ConfigurationTemplate != "" && i.ConfigurationTemplate == defaultConfigurationTemplate {
+		i.ConfigurationTemplate = prof.Metadata.ConfigurationTemplate
+	}
+	if prof.Metadata.DatasetTemplate != "" && i.DatasetTemplate == defaultDatasetTemplate {
+		i.DatasetTemplate = prof.Metadata.DatasetTemplate
 	}
-	return i.createSiteWhereInstance(prof)
+	return prof, nil
 }
 
-func (i *CreateInstance) createSiteWhereInstance(prof profile.SiteWhereProfile) (*instance.CreateSiteWhereInstance, error) {
+func (i *CreateInstance) createSiteWhereInstance(prof *config.Profile) (*instance.CreateSiteWhereInstance, error) {
 	inr, err := i.createInstanceResources(prof)
 	if err != nil {
 		return nil, err
 	}
 	return &instance.CreateSiteWhereInstance{
 		InstanceName:               i.InstanceName,
+		Namespace:                  i.Namespace,
 		Tag:                        i.Tag,
 		Replicas:                   i.Replicas,
 		Debug:                      i.Debug,
+		DebugMicroservices:         i.DebugMicroservices,
 		ConfigurationTemplate:      i.ConfigurationTemplate,
 		DatasetTemplate:            i.DatasetTemplate,
+		Profile:                    prof.Name,
 		InstanceCustomResourceName: inr.InstanceName,
 	}, nil
 }
@@ -150,7 +277,7 @@ func (i *CreateInstance) ExtractInstanceName(args []string) (string, error) {
 	return args[0], nil
 }
 
-func (i *CreateInstance) createInstanceResources(profile profile.SiteWhereProfile) (*instanceResourcesResult, error) {
+func (i *CreateInstance) createInstanceResources(prof *config.Profile) (*instanceResourcesResult, error) {
 	var err error
 
 	client, err := ControllerClient(i.cfg)
@@ -158,7 +285,7 @@ func (i *CreateInstance) createInstanceResources(profile profile.SiteWhereProfil
 		return nil, err
 	}
 
-	swInstanceCR, err := i.buildCRSiteWhereInstace(profile)
+	swInstanceCR, err := i.buildCRSiteWhereInstace(prof)
 	if err != nil {
 		return nil, err
 	}
@@ -176,18 +303,26 @@ func (i *CreateInstance) createInstanceResources(profile profile.SiteWhereProfil
 	}, nil
 }
 
-func (i *CreateInstance) buildCRSiteWhereInstace(profile profile.SiteWhereProfile) (*sitewhereiov1alpha4.SiteWhereInstance, error) {
+func (i *CreateInstance) buildCRSiteWhereInstace(prof *config.Profile) (*sitewhereiov1alpha4.SiteWhereInstance, error) {
 	var placeHolder *config.PlaceHolder = &config.PlaceHolder{
 		InstanceName: i.InstanceName,
 		Replicas:     i.Replicas,
 		Tag:          i.Tag,
 		Registry:     i.Registry,
 		Repository:   "sitewhere",
+		Keycloak:     i.settings.Infrastructure.KeycloakEndpoint(),
+		Kafka:        i.settings.Infrastructure.KafkaEndpoint(),
+		PostgreSQL:   i.settings.Infrastructure.PostgreSQLEndpoint(),
+		InfluxDB:     i.settings.Infrastructure.InfluxDBEndpoint(),
+		Redis:        i.settings.Infrastructure.RedisEndpoint(),
 	}
-	conf, err := config.LoadConfigurationOrDefault(placeHolder, profile)
+	conf, err := config.LoadProfileConfiguration(prof, placeHolder)
 	if err != nil {
 		return nil, err
 	}
+	if err = enableDebug(conf.Microservices, i.Debug, i.DebugMicroservices); err != nil {
+		return nil, err
+	}
 	return &sitewhereiov1alpha4.SiteWhereInstance{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       sitewhereiov1alpha4.SiteWhereInstanceKind,
@@ -20,13 +20,13 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
 	"github.com/sitewhere/swctl/pkg/tenant"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	k8sClient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	"helm.sh/helm/v3/pkg/action"
 )
@@ -46,6 +46,16 @@ type CreateTenant struct {
 	ConfigurationTemplate string
 	// DatasetTemplate is the dataset template used for the tenant
 	DatasetTemplate string
+	// Wait for the tenant engines to bootstrap
+	Wait bool
+	// Timeout to wait for the tenant engines to bootstrap
+	Timeout time.Duration
+	// Progress is called when the status of a microservice Deployment changes
+	Progress ProgressFunc
+	// BootstrapProgress is called when the bootstrap state of a tenant engine changes
+	BootstrapProgress BootstrapProgressFunc
+
+	pollInterval time.Duration
 }
 
 type tenantResourcesResult struct {
@@ -61,6 +71,11 @@ func NewCreateTenant(cfg *action.Configuration) *CreateTenant {
 		TenantName:            "",
 		ConfigurationTemplate: "default",
 		DatasetTemplate:       "construction",
+		Wait:                  false,
+		Timeout:               defaultBootstrapTimeout,
+		Progress:              nil,
+		BootstrapProgress:     nil,
+		pollInterval:          installPollInterval,
 	}
 }
 
@@ -76,14 +91,13 @@ func (i *CreateTenant) Run() (*tenant.CreateSiteWhereTenant, error) {
 		return nil, err
 	}
 
-	var swInstance sitewhereiov1alpha4.SiteWhereInstance
-	err = client.Get(context.TODO(), k8sClient.ObjectKey{Name: i.InstanceName}, &swInstance)
+	ctx := context.TODO()
+	_, namespace, err := getInstance(ctx, i.cfg, client, i.InstanceName)
 	if err != nil {
 		return nil, err
 	}
 
-	swTenantCR := i.buildCRSiteWhereTenant()
-	ctx := context.TODO()
+	swTenantCR := i.buildCRSiteWhereTenant(namespace)
 
 	if err := client.Create(ctx, swTenantCR); err != nil {
 		if apierrors.IsAlreadyExists(err) {
@@ -93,13 +107,29 @@ func (i *CreateTenant) Run() (*tenant.CreateSiteWhereTenant, error) {
 		}
 	}
 
-	return &tenant.CreateSiteWhereTenant{
+	result := &tenant.CreateSiteWhereTenant{
 		InstanceName: i.InstanceName,
 		TenantName:   i.TenantName,
-	}, nil
+	}
+	if !i.Wait {
+		return result, nil
+	}
+	clientset, err := i.cfg.KubernetesClientSet()
+	if err != nil {
+		return result, err
+	}
+	waiter := newBootstrapWaiter(i.pollInterval, i.Progress, i.BootstrapProgress)
+	err = waiter.wait(ctx, i.Timeout, func(ctx context.Context) (bool, error) {
+		return waiter.pollTenantBootstrap(ctx, client, clientset, i.TenantName, namespace)
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Bootstrapped = true
+	return result, nil
 }
 
-func (i *CreateTenant) buildCRSiteWhereTenant() *sitewhereiov1alpha4.SiteWhereTenant {
+func (i *CreateTenant) buildCRSiteWhereTenant(namespace string) *sitewhereiov1alpha4.SiteWhereTenant {
 	return &sitewhereiov1alpha4.SiteWhereTenant{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       sitewhereiov1alpha4.SiteWhereTenantKind,
@@ -107,7 +137,7 @@ func (i *CreateTenant) buildCRSiteWhereTenant() *sitewhereiov1alpha4.SiteWhereTe
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      i.TenantName,
-			Namespace: i.InstanceName,
+			Namespace: namespace,
 		},
 		Spec: sitewhereiov1alpha4.SiteWhereTenantSpec{
 			Name:                  i.TenantName,
@@ -0,0 +1,129 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
+
+	"helm.sh/helm/v3/pkg/action"
+)
+
+// Debug is the action for forwarding the JDWP and JMX ports of a
+// SiteWhere Microservice to localhost
+type Debug struct {
+	cfg *action.Configuration
+
+	// Name of the Instance
+	InstanceName string
+	// Name of the Microservice in the instance
+	MicroserviceName string
+	// JDWPPort is the local port of JDWP, the microservice port if 0
+	JDWPPort int
+	// JMXPort is the local port of JMX, the microservice port if 0
+	JMXPort int
+	// StopCh stops the port forwarding when closed
+	StopCh <-chan struct{}
+	// ReadyCh is closed when the ports are forwarded
+	ReadyCh chan struct{}
+	// Out receives the forwarded ports
+	Out io.Writer
+}
+
+// NewDebug constructs a new *Debug
+func NewDebug(cfg *action.Configuration) *Debug {
+	return &Debug{
+		cfg:              cfg,
+		InstanceName:     "",
+		MicroserviceName: "",
+		JDWPPort:         0,
+		JMXPort:          0,
+		StopCh:           nil,
+		ReadyCh:          nil,
+		Out:              os.Stdout,
+	}
+}
+
+// Run forwards the debug ports of the microservice until StopCh is closed
+func (i *Debug) Run() error {
+	if err := i.cfg.KubeClient.IsReachable(); err != nil {
+		return err
+	}
+	client, err := ControllerClient(i.cfg)
+	if err != nil {
+		return err
+	}
+	swMicroservice, pod, err := microservicePod(context.TODO(), i.cfg, client, i.InstanceName, i.MicroserviceName, true)
+	if err != nil {
+		return err
+	}
+	debugSpec := swMicroservice.Spec.Debug
+	if debugSpec == nil || !debugSpec.Enabled {
+		return fmt.Errorf("the debug mode of Microservice %s for Instance %s is not enabled, use swctl update instance %s --debug-ms %s",
+			i.MicroserviceName, i.InstanceName, i.InstanceName, swMicroservice.Spec.FunctionalArea)
+	}
+	ports := debugPorts(debugSpec, i.JDWPPort, i.JMXPort)
+	return forwardPorts(i.cfg, pod, []string{"localhost"}, ports, i.StopCh, i.ReadyCh, i.Out, i.Out)
+}
+
+// debugPorts returns the LOCAL:REMOTE JDWP and JMX ports to forward
+func debugPorts(debugSpec *sitewhereiov1alpha4.MicroserviceDebugSpecification, jdwpPort int, jmxPort int) []string {
+	if jdwpPort == 0 {
+		jdwpPort = debugSpec.JDWPPort
+	}
+	if jmxPort == 0 {
+		jmxPort = debugSpec.JMXPort
+	}
+	return []string{
+		fmt.Sprintf("%d:%d", jdwpPort, debugSpec.JDWPPort),
+		fmt.Sprintf("%d:%d", jmxPort, debugSpec.JMXPort),
+	}
+}
+
+// enableDebug enables the debug mode of the microservices of the functional
+// areas, or of every microservice if all is true
+func enableDebug(microservices []sitewhereiov1alpha4.SiteWhereMicroserviceSpec, all bool, functionalAreas []string) error {
+	for _, area := range functionalAreas {
+		if !hasFunctionalArea(microservices, area) {
+			return fmt.Errorf("unknown microservice '%s' for debug mode", area)
+		}
+	}
+	for idx := range microservices {
+		ms := &microservices[idx]
+		if !all && !containsString(functionalAreas, ms.FunctionalArea) {
+			continue
+		}
+		if ms.Debug == nil || ms.Debug.JDWPPort == 0 {
+			return fmt.Errorf("microservice '%s' has no debug ports", ms.FunctionalArea)
+		}
+		ms.Debug.Enabled = true
+	}
+	return nil
+}
+
+func hasFunctionalArea(microservices []sitewhereiov1alpha4.SiteWhereMicroserviceSpec, area string) bool {
+	for _, ms := range microservices {
+		if ms.FunctionalArea == area {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"testing"
+
+	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
+)
+
+func debugMicroservices() []sitewhereiov1alpha4.SiteWhereMicroserviceSpec {
+	return []sitewhereiov1alpha4.SiteWhereMicroserviceSpec{
+		{
+			FunctionalArea: "instance-management",
+			Debug:          &sitewhereiov1alpha4.MicroserviceDebugSpecification{JDWPPort: 8006, JMXPort: 1106},
+		},
+		{
+			FunctionalArea: "device-management",
+			Debug:          &sitewhereiov1alpha4.MicroserviceDebugSpecification{JDWPPort: 8004, JMXPort: 1104},
+		},
+	}
+}
+
+func TestEnableDebug(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		name            string
+		all             bool
+		functionalAreas []string
+		expected        []bool
+		err             bool
+	}{
+		{name: "disabled", expected: []bool{false, false}},
+		{name: "all", all: true, expected: []bool{true, true}},
+		{name: "functional-area", functionalAreas: []string{"device-management"}, expected: []bool{false, true}},
+		{name: "unknown-functional-area", functionalAreas: []string{"asset-management"}, err: true},
+	}
+	for _, single := range data {
+		t.Run(single.name, func(single struct {
+			name            string
+			all             bool
+			functionalAreas []string
+			expected        []bool
+			err             bool
+		}) func(t *testing.T) {
+			return func(t *testing.T) {
+				microservices := debugMicroservices()
+				err := enableDebug(microservices, single.all, single.functionalAreas)
+				if single.err {
+					if err == nil {
+						t.Fatalf("expected error")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf(err.Error())
+				}
+				for i, enabled := range single.expected {
+					if microservices[i].Debug.Enabled != enabled {
+						t.Fatalf("expected debug %t for %s, got %t", enabled, microservices[i].FunctionalArea, microservices[i].Debug.Enabled)
+					}
+				}
+			}
+		}(single))
+	}
+}
+
+func TestDebugPorts(t *testing.T) {
+	debugSpec := &sitewhereiov1alpha4.MicroserviceDebugSpecification{Enabled: true, JDWPPort: 8006, JMXPort: 1106}
+	ports := debugPorts(debugSpec, 0, 9999)
+	if len(ports) != 2 || ports[0] != "8006:8006" || ports[1] != "9999:1106" {
+		t.Fatalf("unexpected ports %v", ports)
+	}
+}
@@ -18,21 +18,14 @@ package action
 
 import (
 	"context"
-	"fmt"
 	"strings"
 
 	"github.com/pkg/errors"
 
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/types"
-
 	_ "k8s.io/client-go/plugin/pkg/client/auth"     // Auth
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp" // GPC Auth
 
 	"github.com/sitewhere/swctl/pkg/instance"
-	"github.com/sitewhere/swctl/pkg/resources"
-
-	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
 
 	"helm.sh/helm/v3/pkg/action"
 )
@@ -65,31 +58,38 @@ func (i *DeleteInstance) Run() (*instance.DeleteSiteWhereInstance, error) {
 		return nil, err
 	}
 	ctx := context.TODO()
-	var swInstance sitewhereiov1alpha4.SiteWhereInstance
-	if err := client.Get(ctx, types.NamespacedName{Name: i.InstanceName}, &swInstance); err != nil {
-		if apierrors.IsNotFound(err) {
-			return nil, fmt.Errorf("sitewhere instance '%s' not found", i.InstanceName)
-		}
+	swInstance, namespace, err := getInstance(ctx, i.cfg, client, i.InstanceName)
+	if err != nil {
 		return nil, err
 	}
-	if err := client.Delete(ctx, &swInstance); err != nil {
+	// The controlled namespace is resolved before deleting the instance,
+	// since garbage collection starts terminating it right after. Only this
+	// namespace is deleted, never a namespace that happens to have the
+	// instance name.
+	var purged string
+	clientset, err := i.cfg.KubernetesClientSet()
+	if err != nil {
 		return nil, err
 	}
-
 	if i.Purge {
-		clientset, err := i.cfg.KubernetesClientSet()
+		purged, err = instanceControlledNamespace(ctx, clientset, swInstance)
 		if err != nil {
 			return nil, err
 		}
-		err = resources.DeleteNamespaceIfExists(i.InstanceName, clientset)
-		if err != nil {
+	}
+	if err := client.Delete(ctx, swInstance); err != nil {
+		return nil, err
+	}
+	if purged != "" {
+		if err := deleteNamespace(ctx, clientset, purged); err != nil {
 			return nil, err
 		}
 	}
 
 	return &instance.DeleteSiteWhereInstance{
-		InstanceName: i.InstanceName,
-		Namespace:    i.InstanceName,
+		InstanceName:    i.InstanceName,
+		Namespace:       namespace,
+		PurgedNamespace: purged,
 	}, nil
 }
 
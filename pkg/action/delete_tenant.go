@@ -60,13 +60,17 @@ func (i *DeleteTenant) Run() (*tenant.CreateSiteWhereTenant, error) {
 		return nil, err
 	}
 
-	var swTenantCR sitewhereiov1alpha4.SiteWhereTenant
-	err = client.Get(context.TODO(), k8sClient.ObjectKey{Namespace: i.InstanceName, Name: i.TenantName}, &swTenantCR)
+	ctx := context.TODO()
+	_, namespace, err := getInstance(ctx, i.cfg, client, i.InstanceName)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx := context.TODO()
+	var swTenantCR sitewhereiov1alpha4.SiteWhereTenant
+	err = client.Get(ctx, k8sClient.ObjectKey{Namespace: namespace, Name: i.TenantName}, &swTenantCR)
+	if err != nil {
+		return nil, err
+	}
 
 	if err := client.Delete(ctx, &swTenantCR); err != nil {
 		if apierrors.IsNotFound(err) {
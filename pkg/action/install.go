@@ -19,14 +19,15 @@ package action
 import (
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/sitewhere/swctl/pkg/cli"
 	"github.com/sitewhere/swctl/pkg/install/profile"
 
 	"gopkg.in/yaml.v2"
@@ -36,67 +37,69 @@ import (
 
 	"github.com/sitewhere/swctl/pkg/config"
 	"github.com/sitewhere/swctl/pkg/install"
-	"github.com/sitewhere/swctl/pkg/resources"
+	"github.com/sitewhere/swctl/pkg/preflight"
+	"github.com/sitewhere/swctl/pkg/status"
 
 	"helm.sh/helm/v3/pkg/action"
-	"helm.sh/helm/v3/pkg/chart"
-	"helm.sh/helm/v3/pkg/chart/loader"
-	"helm.sh/helm/v3/pkg/cli"
-	"helm.sh/helm/v3/pkg/cli/values"
-	"helm.sh/helm/v3/pkg/downloader"
 	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/repo"
 )
 
 const defaultHelmChartVersion string = "0.1.13"
 
+// defaultInstallTimeout is the time to wait for the install to complete
+const defaultInstallTimeout = 300 * time.Second
+
 // Install is the action for installing SiteWhere
 type Install struct {
 	cfg *action.Configuration
 
 	settings *cli.EnvSettings
 
-	// HelmChartVersion is the version of SiteWhere Infrastructure Helm Chart
-	HelmChartVersion string
+	InfrastructureValues
+
+	ChartSource
 
 	// SkipCRD indicates if we need to install SiteWhere Custom Resource Definitions
 	SkipCRD bool
-	// SkipTemplate indicates if we need to install SiteWhere templates
-	SkipTemplate bool
-	// SkipOperator indicates if we need to install SiteWhere Operator
-	SkipOperator bool
-	// SkipInfrastructure indicates if we need to install SiteWhere Infrastructure
-	SkipInfrastructure bool
 	// Wait for components to be ready before return control.
 	WaitReady bool
+	// Atomic if true, rollback the release, the CRDs and the namespace
+	// created by the install when it fails
+	Atomic bool
+	// Timeout to wait for the install to complete
+	Timeout time.Duration
+	// Progress is called when the status of a component changes while waiting
+	Progress ProgressFunc
+	// Out receives the pre-flight warnings
+	Out io.Writer
 	// Use verbose mode
 	Verbose bool
-	// Minimal if true, deploy minimal infrastucure
-	Minimal bool
-	// StorageClass is the name of the storage class for the infrastructure
-	StorageClass string
-	// KafkaPVCStorageSize is the size of Kafka PVC Storage Size
-	KafkaPVCStorageSize string
-	// InfluxDBPVCStorageSize is the size of InfluxDB PVC Storage Size
-	InfluxDBPVCStorageSize string
+	// SkipPreflight skips the pre-flight checks
+	SkipPreflight bool
+	// DryRun if true, render the manifests without contacting the cluster
+	DryRun bool
+	// OutputDir is the directory where the rendered manifests are written
+	OutputDir string
 }
 
 // NewInstall constructs a new *Install
 func NewInstall(cfg *action.Configuration, settings *cli.EnvSettings) *Install {
 	return &Install{
-		cfg:                    cfg,
-		settings:               settings,
-		HelmChartVersion:       defaultHelmChartVersion,
-		SkipCRD:                false,
-		SkipTemplate:           false,
-		SkipOperator:           false,
-		SkipInfrastructure:     false,
-		WaitReady:              false,
-		Verbose:                false,
-		Minimal:                false,
-		StorageClass:           "",
-		KafkaPVCStorageSize:    "",
-		InfluxDBPVCStorageSize: "",
+		cfg:                  cfg,
+		settings:             settings,
+		InfrastructureValues: newInfrastructureValues(),
+		ChartSource:          newChartSource(),
+		SkipCRD:              false,
+		WaitReady:            false,
+		Atomic:               false,
+		Timeout:              defaultInstallTimeout,
+		Progress:             nil,
+		Out:                  os.Stdout,
+		Verbose:              false,
+		SkipPreflight:        false,
+		DryRun:               false,
+		OutputDir:            "",
 	}
 }
 
@@ -109,15 +112,15 @@ func (i *Install) Run() (*install.SiteWhereInstall, error) {
 			return nil, err
 		}
 	}
-	err = i.CheckInstallPrerequisites()
+	err = i.ApplyProfile()
 	if err != nil {
 		return nil, err
 	}
-	err = i.addSiteWhereRepository()
+	err = i.CheckInstallPrerequisites()
 	if err != nil {
 		return nil, err
 	}
-	err = i.updateSiteWhereRepository()
+	err = i.prepareRepository(i.settings)
 	if err != nil {
 		return nil, err
 	}
@@ -140,30 +143,22 @@ func (i *Install) CreateConfiguration() error {
 	return config.CreateDefaultConfiguration()
 }
 
-// CheckInstallPrerequisites checks for SiteWhere Install Prerequisites
+// CheckInstallPrerequisites runs the install pre-flight checks and returns
+// an error if any of them fails
 func (i *Install) CheckInstallPrerequisites() error {
-	var err error
-	// check for kubernetes cluster
-	if err = i.cfg.KubeClient.IsReachable(); err != nil {
-		return err
-	}
-	clientSet, err := i.cfg.KubernetesClientSet()
-	if err != nil {
-		return err
+	if i.SkipPreflight {
+		return nil
 	}
-	// check for Istio installed on the cluster
-	ok, err := resources.CheckIfExistsNamespace("istio-system", clientSet)
+	report, err := runPreflight(i.cfg, preflight.InstallChecks(), i.StorageClass, i.Minimal)
 	if err != nil {
 		return err
 	}
-	if !ok {
-		return errors.Errorf(ErrIstioNotInstalled)
-	}
-	return nil
+	writePreflightWarnings(i.Out, report)
+	return report.Err()
 }
 
-func (i *Install) addSiteWhereRepository() error {
-	repoFile := i.settings.RepositoryConfig
+func addSiteWhereRepository(settings *cli.EnvSettings) error {
+	repoFile := settings.RepositoryConfig
 
 	//Ensure the file directory exists as it is required for file locking
 	err := os.MkdirAll(filepath.Dir(repoFile), os.ModePerm)
@@ -193,23 +188,29 @@ func (i *Install) addSiteWhereRepository() error {
 		return err
 	}
 
-	if f.Has(sitewhereRepoName) {
-		fmt.Printf("repository name (%s) already exists\n", sitewhereRepoName)
-		return nil
-	}
-
 	c := repo.Entry{
-		Name: sitewhereRepoName,
-		URL:  sitewhereRepoURL,
+		Name:                  settings.Repository.Name,
+		URL:                   settings.Repository.URL,
+		Username:              settings.Repository.Username,
+		Password:              settings.Repository.Password,
+		CAFile:                settings.Repository.CAFile,
+		CertFile:              settings.Repository.CertFile,
+		KeyFile:               settings.Repository.KeyFile,
+		InsecureSkipTLSverify: settings.Repository.InsecureSkipTLSVerify,
+	}
+
+	if existing := f.Get(c.Name); existing != nil && *existing == c {
+		fmt.Printf("repository name (%s) already exists\n", c.Name)
+		return nil
 	}
 
-	r, err := repo.NewChartRepository(&c, getter.All(i.settings))
+	r, err := repo.NewChartRepository(&c, getter.All(settings.EnvSettings))
 	if err != nil {
 		return err
 	}
 
 	if _, err := r.DownloadIndexFile(); err != nil {
-		err := errors.Wrapf(err, "looks like %q is not a valid chart repository or cannot be reached", sitewhereRepoURL)
+		err := errors.Wrapf(err, "looks like %q is not a valid chart repository or cannot be reached", c.URL)
 		return err
 	}
 
@@ -221,8 +222,8 @@ func (i *Install) addSiteWhereRepository() error {
 	return nil
 }
 
-func (i *Install) updateSiteWhereRepository() error {
-	repoFile := i.settings.RepositoryConfig
+func updateSiteWhereRepository(settings *cli.EnvSettings) error {
+	repoFile := settings.RepositoryConfig
 
 	f, err := repo.LoadFile(repoFile)
 	if os.IsNotExist(errors.Cause(err)) || len(f.Repositories) == 0 {
@@ -230,7 +231,7 @@ func (i *Install) updateSiteWhereRepository() error {
 	}
 	var repos []*repo.ChartRepository
 	for _, cfg := range f.Repositories {
-		r, err := repo.NewChartRepository(cfg, getter.All(i.settings))
+		r, err := repo.NewChartRepository(cfg, getter.All(settings.EnvSettings))
 		if err != nil {
 			return err
 		}
@@ -256,17 +257,8 @@ func (i *Install) updateSiteWhereRepository() error {
 }
 
 func (i *Install) installRelease() (*install.SiteWhereInstall, error) {
-	actionConfig := new(action.Configuration)
-	// You can pass an empty string instead of settings.Namespace() to list
-	// all namespaces
-	var logConf action.DebugLog
-	if i.Verbose {
-		logConf = log.Printf
-	} else {
-		logConf = Discardf
-	}
-
-	if err := actionConfig.Init(i.settings.RESTClientGetter(), sitewhereSystemNamespace, os.Getenv("HELM_DRIVER"), logConf); err != nil {
+	actionConfig, err := newReleaseConfiguration(i.settings, i.Verbose)
+	if err != nil {
 		return nil, err
 	}
 
@@ -274,197 +266,200 @@ func (i *Install) installRelease() (*install.SiteWhereInstall, error) {
 	if installAction.Version == "" && installAction.Devel {
 		installAction.Version = ">0.0.0-0"
 	}
-	installAction.Namespace = sitewhereSystemNamespace
-	installAction.ReleaseName = sitewhereReleaseName
+	installAction.Namespace = i.settings.SystemNamespace
+	installAction.ReleaseName = i.settings.ReleaseName
 	installAction.CreateNamespace = true
 	installAction.SkipCRDs = i.SkipCRD
-	installAction.Wait = i.WaitReady
+	// swctl waits for the components itself to report their progress
+	installAction.Wait = false
+	installAction.Timeout = i.Timeout
 	installAction.Version = i.HelmChartVersion
 
-	cp, err := installAction.ChartPathOptions.LocateChart(fmt.Sprintf("%s/%s", sitewhereRepoName, sitewhereChartName), i.settings)
-
-	p := getter.All(i.settings)
-	valueOpts := &values.Options{}
-	vals, err := valueOpts.MergeValues(p)
+	vals, err := i.MergeValues(getter.All(i.settings.EnvSettings))
 	if err != nil {
 		return nil, err
 	}
 
-	// Skip operator
-	vals["operator"] = map[string]interface{}{
-		"enabled": !i.SkipOperator,
+	chartRequested, err := loadSiteWhereChart(&installAction.ChartPathOptions, i.ChartPath, installAction.DependencyUpdate, i.settings)
+	if err != nil {
+		return nil, err
 	}
 
-	// Skip templates
-	vals["templates"] = map[string]interface{}{
-		"enabled": !i.SkipTemplate,
+	var snapshot *installSnapshot
+	var crdNames []string
+	if i.Atomic {
+		if !i.SkipCRD {
+			if crdNames, err = chartCRDNames(chartRequested); err != nil {
+				return nil, err
+			}
+		}
+		if snapshot, err = i.takeSnapshot(crdNames); err != nil {
+			return nil, err
+		}
 	}
 
-	// Skip infrastructure
-	vals["tags"] = map[string]interface{}{
-		"infrastructure": !i.SkipInfrastructure,
-	}
-	vals["postgresql"] = map[string]interface{}{
-		"enabled": !i.SkipInfrastructure,
-	}
-	vals["influxdb"] = map[string]interface{}{
-		"enabled": !i.SkipInfrastructure,
-	}
-	vals["redis"] = map[string]interface{}{
-		"enabled": !i.SkipInfrastructure,
-	}
-	vals["nifi"] = map[string]interface{}{
-		"enabled": !i.SkipInfrastructure,
-	}
-	vals["mosquitto"] = map[string]interface{}{
-		"enabled": !i.SkipInfrastructure,
-	}
-	vals["strimzi"] = map[string]interface{}{
-		"enabled": !i.SkipInfrastructure,
-	}
-	vals["keycloak"] = map[string]interface{}{
-		"enabled": !i.SkipInfrastructure,
-	}
-	if i.Minimal {
-		vals["strimzi"] = map[string]interface{}{
-			"replicas": 1,
-			"isr":      1,
+	ctx, cancel := context.WithTimeout(context.Background(), i.Timeout)
+	defer cancel()
+
+	res, err := installAction.Run(chartRequested, vals)
+
+	var components []status.SiteWhereStatus
+	if err == nil {
+		if i.WaitReady || i.Atomic {
+			components, err = waitForComponents(ctx, installPollInterval, i.componentStatus, i.Progress)
+		} else {
+			components, err = i.componentStatus()
 		}
 	}
 
-	// set storage class
-	if i.StorageClass != "" {
-		// InfluxDB storage class
-		vals["influxdb"] = map[string]interface{}{
-			"persistence": map[string]interface{}{
-				"storageClass": i.StorageClass,
-			},
+	if err != nil {
+		if !i.Atomic {
+			if res == nil {
+				return nil, err
+			}
+			return &install.SiteWhereInstall{
+				Release:        res.Name,
+				Namespace:      res.Namespace,
+				Components:     components,
+				Infrastructure: i.infrastructureStatus(components),
+			}, err
 		}
-		// Redis
-		vals["redis"] = map[string]interface{}{
-			"global": map[string]interface{}{
-				"storageClass": i.StorageClass,
-			},
-			"master": map[string]interface{}{
-				"persistence": map[string]interface{}{
-					"storageClass": i.StorageClass,
-				},
-			},
-			"slave": map[string]interface{}{
-				"persistence": map[string]interface{}{
-					"storageClass": i.StorageClass,
-				},
-			},
+		rolledBack, rollbackErr := i.rollbackInstall(actionConfig, snapshot, crdNames)
+		if rollbackErr != nil {
+			return nil, errors.Wrapf(err, "install failed and rollback failed: %s", rollbackErr)
 		}
-		// Nifi
-		vals["nifi"] = map[string]interface{}{
-			"persistence": map[string]interface{}{
-				"storageClass": i.StorageClass,
-			},
-			"zookeeper": map[string]interface{}{
-				"global": map[string]interface{}{
-					"storageClass": i.StorageClass,
-				},
-				"persistence": map[string]interface{}{
-					"storageClass": i.StorageClass,
-				},
-			},
+		return &install.SiteWhereInstall{
+			Release:        i.settings.ReleaseName,
+			Namespace:      i.settings.SystemNamespace,
+			Components:     components,
+			Infrastructure: i.infrastructureStatus(components),
+			RolledBack:     rolledBack,
+		}, errors.Wrap(err, "install failed and has been rolled back")
+	}
+
+	return &install.SiteWhereInstall{
+		Release:        res.Name,
+		Namespace:      res.Namespace,
+		Components:     components,
+		Infrastructure: i.infrastructureStatus(components),
+	}, nil
+}
+
+// infrastructureStatus returns the status of each infrastructure component
+// from the status of its workloads
+func (i *Install) infrastructureStatus(components []status.SiteWhereStatus) []status.SiteWhereStatus {
+	selected, err := i.SelectedComponents()
+	if err != nil {
+		return nil
+	}
+	return infrastructureStatus(selected, components)
+}
+
+func infrastructureStatus(selected map[install.Component]bool, components []status.SiteWhereStatus) []status.SiteWhereStatus {
+	var result []status.SiteWhereStatus
+	for _, info := range install.Components() {
+		infraStatus := status.SiteWhereStatus{
+			Name: string(info.Component),
+			Kind: infrastructureKind,
 		}
-		// PostgreSQL
-		vals["postgresql"] = map[string]interface{}{
-			"global": map[string]interface{}{
-				"storageClass": i.StorageClass,
-			},
-			"persistence": map[string]interface{}{
-				"storageClass": i.StorageClass,
-			},
+		if !selected[info.Component] {
+			infraStatus.Status = status.Skipped
+			result = append(result, infraStatus)
+			continue
 		}
-		// Keycloak
-		vals["keycloak"] = map[string]interface{}{
-			"postgresql": map[string]interface{}{
-				"persistence": map[string]interface{}{
-					"storageClass": i.StorageClass,
-				},
-			},
+		var total, ready int
+		for _, component := range components {
+			if component.Kind != deploymentKind && component.Kind != statefulSetKind && component.Kind != kafkaKind {
+				continue
+			}
+			if c, ok := install.MatchComponent(component.Name); !ok || c != info.Component {
+				continue
+			}
+			total++
+			if component.Status == status.Installed {
+				ready++
+			}
 		}
-		// Strimzi
-		vals["strimzi"] = map[string]interface{}{
-			"storage": map[string]interface{}{
-				"type":         "persistent-claim",
-				"storageClass": i.StorageClass,
-			},
+		if total == 0 {
+			infraStatus.Status = status.NotReady
+			infraStatus.Detail = "no workloads found"
+		} else {
+			infraStatus.Detail = fmt.Sprintf("%d/%d workloads ready", ready, total)
+			if ready == total {
+				infraStatus.Status = status.Installed
+			} else {
+				infraStatus.Status = status.NotReady
+			}
 		}
+		result = append(result, infraStatus)
 	}
+	return result
+}
 
-	// KafkaPVCStorageSize
-	if i.KafkaPVCStorageSize != "" {
-		vals["strimzi"] = map[string]interface{}{
-			"storage": map[string]interface{}{
-				"size": i.KafkaPVCStorageSize,
-			},
-		}
+// componentStatus returns the readiness of the installed CRDs, workloads
+// and Strimzi Kafka clusters
+func (i *Install) componentStatus() ([]status.SiteWhereStatus, error) {
+	clientset, err := i.cfg.KubernetesClientSet()
+	if err != nil {
+		return nil, err
 	}
-
-	// InfluxDBPVCStorageSize
-	if i.InfluxDBPVCStorageSize != "" {
-		vals["influxdb"] = map[string]interface{}{
-			"persistence": map[string]interface{}{
-				"size": i.InfluxDBPVCStorageSize,
-			},
+	dynamicClient, err := KubernetesDynamicClientSet(i.cfg)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.TODO()
+	var components []status.SiteWhereStatus
+	if !i.SkipCRD {
+		extensionsClient, err := KubernetesAPIExtensionClientSet(i.cfg)
+		if err != nil {
+			return nil, err
+		}
+		crdStatuses, err := checkCRDs(ctx, extensionsClient)
+		if err != nil {
+			return nil, err
 		}
+		components = append(components, crdStatuses...)
 	}
-
-	// Check chart dependencies to make sure all are present in /charts
-	chartRequested, err := loader.Load(cp)
+	workloadStatuses, err := checkWorkloads(ctx, clientset, i.settings.SystemNamespace, !i.SkipOperator)
 	if err != nil {
 		return nil, err
 	}
-
-	validInstallableChart, err := isChartInstallable(chartRequested)
-	if !validInstallableChart {
+	components = append(components, workloadStatuses...)
+	kafkaStatuses, err := checkKafkas(ctx, dynamicClient, i.settings.SystemNamespace)
+	if err != nil {
 		return nil, err
 	}
+	return append(components, kafkaStatuses...), nil
+}
 
-	if req := chartRequested.Metadata.Dependencies; req != nil {
-		// If CheckDependencies returns an error, we have unfulfilled dependencies.
-		// As of Helm 2.4.0, this is treated as a stopping condition:
-		// https://github.com/helm/helm/issues/2209
-		if err := action.CheckDependencies(chartRequested, req); err != nil {
-			if installAction.DependencyUpdate {
-				man := &downloader.Manager{
-					Out:              os.Stdout,
-					ChartPath:        cp,
-					Keyring:          installAction.ChartPathOptions.Keyring,
-					SkipUpdate:       false,
-					Getters:          p,
-					RepositoryConfig: i.settings.RepositoryConfig,
-					RepositoryCache:  i.settings.RepositoryCache,
-				}
-				if err := man.Update(); err != nil {
-					return nil, err
-				}
-			} else {
-				return nil, err
-			}
-		}
+func (i *Install) takeSnapshot(crdNames []string) (*installSnapshot, error) {
+	clientset, err := i.cfg.KubernetesClientSet()
+	if err != nil {
+		return nil, err
 	}
-
-	res, err := installAction.Run(chartRequested, vals)
-
+	extensionsClient, err := KubernetesAPIExtensionClientSet(i.cfg)
 	if err != nil {
 		return nil, err
 	}
-
-	return &install.SiteWhereInstall{
-		Release:   res.Name,
-		Namespace: res.Namespace,
-	}, nil
+	return takeInstallSnapshot(context.TODO(), clientset, extensionsClient, i.settings.SystemNamespace, crdNames)
 }
 
-func isChartInstallable(ch *chart.Chart) (bool, error) {
-	switch ch.Metadata.Type {
-	case "", "application":
-		return true, nil
+// rollbackInstall uninstalls the release and deletes the CRDs and the
+// namespace created by a failed install
+func (i *Install) rollbackInstall(actionConfig *action.Configuration, snapshot *installSnapshot, crdNames []string) ([]status.SiteWhereStatus, error) {
+	clientset, err := i.cfg.KubernetesClientSet()
+	if err != nil {
+		return nil, err
+	}
+	extensionsClient, err := KubernetesAPIExtensionClientSet(i.cfg)
+	if err != nil {
+		return nil, err
+	}
+	var rolledBack []status.SiteWhereStatus
+	if releaseStatus := uninstallFailedRelease(actionConfig, i.settings.ReleaseName, i.Timeout); releaseStatus != nil {
+		rolledBack = append(rolledBack, *releaseStatus)
 	}
-	return false, errors.Errorf("%s charts are not installable", ch.Metadata.Type)
+	rolledBack = append(rolledBack, deleteCreatedResources(context.TODO(), clientset, extensionsClient,
+		snapshot, i.settings.SystemNamespace, crdNames)...)
+	return rolledBack, nil
 }
@@ -0,0 +1,137 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sitewhere/swctl/pkg/status"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// installSnapshot records the cluster-scoped resources present before
+// an install, so an atomic install only removes what it created.
+type installSnapshot struct {
+	// NamespaceExisted is true if the release namespace was present
+	NamespaceExisted bool
+	// CRDs are the names of the Custom Resource Definitions present
+	CRDs map[string]bool
+}
+
+// takeInstallSnapshot records the release namespace and the given CRDs
+// that are already present in the cluster
+func takeInstallSnapshot(ctx context.Context, clientset kubernetes.Interface,
+	extensionsClient clientset.Interface, namespace string, crdNames []string) (*installSnapshot, error) {
+	snapshot := &installSnapshot{CRDs: map[string]bool{}}
+	_, err := clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err == nil {
+		snapshot.NamespaceExisted = true
+	} else if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	for _, name := range crdNames {
+		_, err := extensionsClient.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			snapshot.CRDs[name] = true
+		} else if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	return snapshot, nil
+}
+
+// chartCRDNames returns the names of the Custom Resource Definitions of a chart
+func chartCRDNames(ch *chart.Chart) ([]string, error) {
+	var names []string
+	for _, crd := range ch.CRDObjects() {
+		for _, manifest := range releaseutil.SplitManifests(string(crd.File.Data)) {
+			var head struct {
+				Kind     string `yaml:"kind"`
+				Metadata struct {
+					Name string `yaml:"name"`
+				} `yaml:"metadata"`
+			}
+			if err := yaml.Unmarshal([]byte(manifest), &head); err != nil {
+				return nil, errors.Wrapf(err, "parsing Custom Resource Definition %s", crd.Filename)
+			}
+			if head.Kind == crdKind && head.Metadata.Name != "" {
+				names = append(names, head.Metadata.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// uninstallFailedRelease uninstalls a release left by a failed install
+func uninstallFailedRelease(actionConfig *action.Configuration, releaseName string, timeout time.Duration) *status.SiteWhereStatus {
+	uninstallAction := action.NewUninstall(actionConfig)
+	uninstallAction.Timeout = timeout
+	_, err := uninstallAction.Run(releaseName)
+	if err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			return nil
+		}
+		return &status.SiteWhereStatus{Name: releaseName, Kind: helmReleaseKind, Status: status.Unknown, Detail: err.Error()}
+	}
+	return &status.SiteWhereStatus{Name: releaseName, Kind: helmReleaseKind, Status: status.Uninstalled, Detail: "release uninstalled"}
+}
+
+// deleteCreatedResources deletes the CRDs and the namespace that were
+// not present in the snapshot, returning what was rolled back
+func deleteCreatedResources(ctx context.Context, clientset kubernetes.Interface, extensionsClient clientset.Interface,
+	snapshot *installSnapshot, namespace string, crdNames []string) []status.SiteWhereStatus {
+	var result []status.SiteWhereStatus
+	for _, name := range crdNames {
+		if snapshot.CRDs[name] {
+			continue
+		}
+		err := extensionsClient.ApiextensionsV1().CustomResourceDefinitions().Delete(ctx, name, metav1.DeleteOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		result = append(result, rolledBackStatus(name, crdKind, err))
+	}
+	if !snapshot.NamespaceExisted {
+		err := clientset.CoreV1().Namespaces().Delete(ctx, namespace, metav1.DeleteOptions{})
+		if !apierrors.IsNotFound(err) {
+			result = append(result, rolledBackStatus(namespace, namespaceKind, err))
+		}
+	}
+	return result
+}
+
+func rolledBackStatus(name string, kind string, err error) status.SiteWhereStatus {
+	if err != nil {
+		return status.SiteWhereStatus{Name: name, Kind: kind, Status: status.Unknown, Detail: err.Error()}
+	}
+	return status.SiteWhereStatus{Name: name, Kind: kind, Status: status.Uninstalled, Detail: "deleted"}
+}
@@ -0,0 +1,101 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	extensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sitewhere/swctl/pkg/cli"
+	"github.com/sitewhere/swctl/pkg/status"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestChartCRDNames(t *testing.T) {
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "test"},
+		Files: []*chart.File{
+			{
+				Name: "crds/sitewhere.yaml",
+				Data: []byte("apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: instances.sitewhere.io\n---\napiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: tenants.sitewhere.io\n"),
+			},
+		},
+	}
+	names, err := chartCRDNames(ch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(names) != 2 || names[0] != "instances.sitewhere.io" || names[1] != "tenants.sitewhere.io" {
+		t.Fatalf("unexpected CRD names: %v", names)
+	}
+}
+
+func TestDeleteCreatedResources(t *testing.T) {
+	t.Parallel()
+	crdNames := []string{"instances.sitewhere.io", "tenants.sitewhere.io"}
+	data := []struct {
+		name     string
+		snapshot *installSnapshot
+		expected []string
+	}{
+		{
+			name:     "all-created",
+			snapshot: &installSnapshot{CRDs: map[string]bool{}},
+			expected: []string{"instances.sitewhere.io", "tenants.sitewhere.io", cli.DefaultNamespace},
+		},
+		{
+			name:     "namespace-existed",
+			snapshot: &installSnapshot{NamespaceExisted: true, CRDs: map[string]bool{"instances.sitewhere.io": true}},
+			expected: []string{"tenants.sitewhere.io"},
+		},
+	}
+	for _, single := range data {
+		t.Run(single.name, func(single struct {
+			name     string
+			snapshot *installSnapshot
+			expected []string
+		}) func(t *testing.T) {
+			return func(t *testing.T) {
+				clientset := fake.NewSimpleClientset(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: cli.DefaultNamespace}})
+				extensionsClient := extensionsfake.NewSimpleClientset(
+					&apiextv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "instances.sitewhere.io"}},
+					&apiextv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "tenants.sitewhere.io"}},
+				)
+				result := deleteCreatedResources(context.TODO(), clientset, extensionsClient, single.snapshot, cli.DefaultNamespace, crdNames)
+				if len(result) != len(single.expected) {
+					t.Fatalf("expected %d rolled back resources, got %v", len(single.expected), result)
+				}
+				for index, name := range single.expected {
+					if result[index].Name != name || result[index].Status != status.Uninstalled {
+						t.Fatalf("expected %s to be rolled back, got %v", name, result[index])
+					}
+				}
+				_, err := extensionsClient.ApiextensionsV1().CustomResourceDefinitions().Get(context.TODO(), "instances.sitewhere.io", metav1.GetOptions{})
+				if single.snapshot.CRDs["instances.sitewhere.io"] && err != nil {
+					t.Fatalf("expected pre-existing CRD to be kept: %v", err)
+				}
+			}
+		}(single))
+	}
+}
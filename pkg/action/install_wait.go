@@ -0,0 +1,122 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/sitewhere/swctl/pkg/status"
+)
+
+const kafkaKind = "Kafka"
+
+// kafkaGVR is the Strimzi Kafka resource
+var kafkaGVR = schema.GroupVersionResource{
+	Group:    "kafka.strimzi.io",
+	Version:  "v1beta1",
+	Resource: "kafkas",
+}
+
+// installPollInterval is the time between two readiness checks while waiting
+const installPollInterval = 2 * time.Second
+
+// ProgressFunc is called with a component each time its status changes
+type ProgressFunc func(component status.SiteWhereStatus)
+
+// checkKafkas checks the readiness of the Strimzi Kafka clusters in the namespace.
+// It returns no status if Strimzi is not installed.
+func checkKafkas(ctx context.Context, dynamicClient dynamic.Interface, namespace string) ([]status.SiteWhereStatus, error) {
+	kafkas, err := dynamicClient.Resource(kafkaGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var result []status.SiteWhereStatus
+	for _, kafka := range kafkas.Items {
+		result = append(result, kafkaStatus(&kafka))
+	}
+	return result, nil
+}
+
+func kafkaStatus(kafka *unstructured.Unstructured) status.SiteWhereStatus {
+	result := status.SiteWhereStatus{
+		Name:   kafka.GetName(),
+		Kind:   kafkaKind,
+		Status: status.NotReady,
+		Detail: "Not Ready",
+	}
+	conditions, _, _ := unstructured.NestedSlice(kafka.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok || cond["type"] != "Ready" {
+			continue
+		}
+		if cond["status"] == "True" {
+			result.Status = status.Installed
+			result.Detail = "Ready"
+		} else if message, ok := cond["message"].(string); ok && message != "" {
+			result.Detail = message
+		}
+	}
+	return result
+}
+
+// waitForComponents polls the status of the components until all of them are
+// installed and ready or the context is done. The progress function is called
+// for every component whose status changed since the last poll.
+func waitForComponents(ctx context.Context, interval time.Duration,
+	poll func() ([]status.SiteWhereStatus, error), progress ProgressFunc) ([]status.SiteWhereStatus, error) {
+	var last = map[string]status.SiteWhereStatus{}
+	for {
+		components, err := poll()
+		if err != nil {
+			return components, err
+		}
+		ready := true
+		for _, component := range components {
+			key := component.Kind + "/" + component.Name
+			if previous, ok := last[key]; !ok || previous.Status != component.Status || previous.Detail != component.Detail {
+				if progress != nil {
+					progress(component)
+				}
+				last[key] = component
+			}
+			if component.Status != status.Installed {
+				ready = false
+			}
+		}
+		if ready {
+			return components, nil
+		}
+		select {
+		case <-ctx.Done():
+			return components, errors.New("timed out waiting for SiteWhere components to be ready")
+		case <-time.After(interval):
+		}
+	}
+}
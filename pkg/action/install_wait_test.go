@@ -0,0 +1,132 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/sitewhere/swctl/pkg/install"
+	"github.com/sitewhere/swctl/pkg/status"
+)
+
+func TestKafkaStatus(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		name       string
+		conditions []interface{}
+		expected   status.Status
+	}{
+		{
+			name:       "no-status",
+			conditions: nil,
+			expected:   status.NotReady,
+		},
+		{
+			name:       "not-ready",
+			conditions: []interface{}{map[string]interface{}{"type": "NotReady", "status": "True"}},
+			expected:   status.NotReady,
+		},
+		{
+			name:       "ready",
+			conditions: []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+			expected:   status.Installed,
+		},
+	}
+	for _, single := range data {
+		t.Run(single.name, func(single struct {
+			name       string
+			conditions []interface{}
+			expected   status.Status
+		}) func(t *testing.T) {
+			return func(t *testing.T) {
+				kafka := &unstructured.Unstructured{Object: map[string]interface{}{}}
+				kafka.SetName("sitewhere-kafka")
+				if single.conditions != nil {
+					unstructured.SetNestedSlice(kafka.Object, single.conditions, "status", "conditions")
+				}
+				result := kafkaStatus(kafka)
+				if result.Status != single.expected {
+					t.Fatalf("expected status: %s got status: %s", single.expected, result.Status)
+				}
+			}
+		}(single))
+	}
+}
+
+func TestWaitForComponents(t *testing.T) {
+	polls := 0
+	poll := func() ([]status.SiteWhereStatus, error) {
+		polls++
+		if polls < 3 {
+			return []status.SiteWhereStatus{{Name: "kafka", Kind: statefulSetKind, Status: status.NotReady, Detail: "0/1 ready"}}, nil
+		}
+		return []status.SiteWhereStatus{{Name: "kafka", Kind: statefulSetKind, Status: status.Installed, Detail: "1/1 ready"}}, nil
+	}
+	var changes []status.SiteWhereStatus
+	progress := func(component status.SiteWhereStatus) {
+		changes = append(changes, component)
+	}
+	components, err := waitForComponents(context.TODO(), time.Millisecond, poll, progress)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(components) != 1 || components[0].Status != status.Installed {
+		t.Fatalf("expected ready components, got %v", components)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 progress changes, got %v", changes)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	polls = 0
+	_, err = waitForComponents(ctx, time.Millisecond, func() ([]status.SiteWhereStatus, error) {
+		return []status.SiteWhereStatus{{Name: "kafka", Kind: statefulSetKind, Status: status.NotReady}}, nil
+	}, nil)
+	if err == nil {
+		t.Fatal("expected timeout error")
+	}
+}
+
+func TestInfrastructureStatus(t *testing.T) {
+	selected := map[install.Component]bool{install.Keycloak: true, install.Redis: true, install.Mosquitto: true}
+	components := []status.SiteWhereStatus{
+		{Name: "sitewhere-keycloak", Kind: statefulSetKind, Status: status.Installed},
+		{Name: "sitewhere-keycloak-postgresql", Kind: statefulSetKind, Status: status.NotReady},
+		{Name: "sitewhere-redis-master", Kind: statefulSetKind, Status: status.Installed},
+	}
+	expected := map[string]status.Status{
+		"postgresql": status.Skipped,
+		"strimzi":    status.Skipped,
+		"keycloak":   status.NotReady,
+		"redis":      status.Installed,
+		"mosquitto":  status.NotReady,
+	}
+	result := infrastructureStatus(selected, components)
+	if len(result) != len(install.Components()) {
+		t.Fatalf("expected a status per component, got %v", result)
+	}
+	for _, component := range result {
+		if s, ok := expected[component.Name]; ok && component.Status != s {
+			t.Fatalf("expected %s status: %s got status: %s (%s)", component.Name, s, component.Status, component.Detail)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
+
+	"github.com/sitewhere/swctl/pkg/instance"
+)
+
+// microservicesHealth joins the microservices of the namespace with their
+// Deployments and Pods
+func microservicesHealth(ctx context.Context, clientset kubernetes.Interface, namespace string,
+	microservices []sitewhereiov1alpha4.SiteWhereMicroservice) ([]instance.MicroserviceHealth, error) {
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var result []instance.MicroserviceHealth
+	for _, swMicroservice := range microservices {
+		var deploy *appsv1.Deployment
+		for idx := range deployments.Items {
+			if deployments.Items[idx].GetName() == swMicroservice.Status.Deployment {
+				deploy = &deployments.Items[idx]
+				break
+			}
+		}
+		result = append(result, microserviceHealth(&swMicroservice, deploy, pods.Items))
+	}
+	return result, nil
+}
+
+// microserviceHealth returns the health of a microservice from its
+// Deployment, nil if not found, and the Pods selected by the Deployment
+func microserviceHealth(swMicroservice *sitewhereiov1alpha4.SiteWhereMicroservice, deploy *appsv1.Deployment, pods []corev1.Pod) instance.MicroserviceHealth {
+	result := instance.MicroserviceHealth{
+		Name:       swMicroservice.GetName(),
+		Namespace:  swMicroservice.GetNamespace(),
+		Deployment: swMicroservice.Status.Deployment,
+		Desired:    swMicroservice.Spec.Replicas,
+	}
+	if deploy == nil {
+		return result
+	}
+	if deploy.Spec.Replicas != nil {
+		result.Desired = *deploy.Spec.Replicas
+	}
+	result.Updated = deploy.Status.UpdatedReplicas
+	result.Ready = deploy.Status.ReadyReplicas
+	result.Available = deploy.Status.AvailableReplicas
+	created := deploy.GetCreationTimestamp()
+	result.Created = &created
+
+	selector, err := metav1.LabelSelectorAsSelector(deploy.Spec.Selector)
+	if err != nil {
+		selector = labels.Nothing()
+	}
+	var lastTermination metav1.Time
+	for _, pod := range pods {
+		if !selector.Matches(labels.Set(pod.GetLabels())) {
+			continue
+		}
+		result.Nodes = appendUnique(result.Nodes, pod.Spec.NodeName)
+		for _, cs := range pod.Status.ContainerStatuses {
+			result.Images = appendUnique(result.Images, cs.Image)
+			result.Tags = appendUnique(result.Tags, imageTag(cs.Image))
+			result.Restarts += cs.RestartCount
+			if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" && cs.State.Waiting.Reason != "ContainerCreating" {
+				// A waiting reason is more recent than any termination
+				result.LastTerminationReason = cs.State.Waiting.Reason
+				lastTermination = metav1.Now()
+				continue
+			}
+			if terminated := cs.LastTerminationState.Terminated; terminated != nil && !terminated.FinishedAt.Before(&lastTermination) {
+				result.LastTerminationReason = terminated.Reason
+				lastTermination = terminated.FinishedAt
+			}
+		}
+	}
+	if len(result.Images) == 0 {
+		for _, container := range deploy.Spec.Template.Spec.Containers {
+			result.Images = appendUnique(result.Images, container.Image)
+			result.Tags = appendUnique(result.Tags, imageTag(container.Image))
+		}
+	}
+	sort.Strings(result.Nodes)
+	return result
+}
+
+// summarizeHealth sums the health of the microservices of an instance
+func summarizeHealth(health []instance.MicroserviceHealth) instance.InstanceSummary {
+	var result instance.InstanceSummary
+	for _, ms := range health {
+		result.Microservices++
+		if ms.Desired > 0 && ms.Available >= ms.Desired {
+			result.ReadyMicroservices++
+		}
+		result.Desired += ms.Desired
+		result.Available += ms.Available
+		result.Restarts += ms.Restarts
+		for _, tag := range ms.Tags {
+			result.Tags = appendUnique(result.Tags, tag)
+		}
+	}
+	sort.Strings(result.Tags)
+	return result
+}
+
+// imageTag returns the tag of an image, latest if it has none
+func imageTag(image string) string {
+	if idx := strings.Index(image, "@"); idx >= 0 {
+		image = image[:idx]
+	}
+	if idx := strings.LastIndex(image, ":"); idx > strings.LastIndex(image, "/") {
+		return image[idx+1:]
+	}
+	return "latest"
+}
+
+func appendUnique(values []string, value string) []string {
+	if value == "" || containsString(values, value) {
+		return values
+	}
+	return append(values, value)
+}
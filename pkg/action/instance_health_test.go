@@ -0,0 +1,190 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	ctlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
+)
+
+func healthPod(name string, app string, node string, status corev1.ContainerStatus) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "sitewhere", Labels: map[string]string{"app": app}},
+		Spec:       corev1.PodSpec{NodeName: node},
+		Status:     corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{status}},
+	}
+}
+
+func TestMicroservicesHealth(t *testing.T) {
+	replicas := int32(2)
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "event-sources", Namespace: "sitewhere"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "event-sources"}},
+		},
+		Status: appsv1.DeploymentStatus{UpdatedReplicas: 2, ReadyReplicas: 1, AvailableReplicas: 1},
+	}
+	earlier := metav1.NewTime(time.Now().Add(-time.Hour))
+	later := metav1.NewTime(time.Now().Add(-time.Minute))
+	clientset := fake.NewSimpleClientset(deploy,
+		healthPod("event-sources-1", "event-sources", "node-b", corev1.ContainerStatus{
+			Image:        "docker.io/sitewhere/service-event-sources:3.0.5",
+			RestartCount: 2,
+			LastTerminationState: corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{Reason: "Error", FinishedAt: earlier},
+			},
+		}),
+		healthPod("event-sources-2", "event-sources", "node-a", corev1.ContainerStatus{
+			Image:        "docker.io/sitewhere/service-event-sources:3.0.5",
+			RestartCount: 3,
+			LastTerminationState: corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", FinishedAt: later},
+			},
+		}),
+		healthPod("device-management-1", "device-management", "node-a", corev1.ContainerStatus{
+			Image:        "docker.io/sitewhere/service-device-management:3.0.4",
+			RestartCount: 7,
+			State: corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+			},
+		}),
+	)
+	microservices := []sitewhereiov1alpha4.SiteWhereMicroservice{
+		rollingMicroservice("event-sources"),
+		rollingMicroservice("device-management"),
+	}
+	health, err := microservicesHealth(context.TODO(), clientset, "sitewhere", microservices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(health) != 2 {
+		t.Fatalf("expected 2 microservices, got %d", len(health))
+	}
+	es := health[0]
+	if es.Desired != 2 || es.Ready != 1 || es.Available != 1 {
+		t.Errorf("expected 2/1/1 replicas, got %d/%d/%d", es.Desired, es.Ready, es.Available)
+	}
+	if len(es.Tags) != 1 || es.Tags[0] != "3.0.5" {
+		t.Errorf("expected tag 3.0.5, got %v", es.Tags)
+	}
+	if es.Restarts != 5 {
+		t.Errorf("expected 5 restarts, got %d", es.Restarts)
+	}
+	if es.LastTerminationReason != "OOMKilled" {
+		t.Errorf("expected OOMKilled, got %s", es.LastTerminationReason)
+	}
+	if len(es.Nodes) != 2 || es.Nodes[0] != "node-a" {
+		t.Errorf("expected sorted nodes, got %v", es.Nodes)
+	}
+	// device-management has no deployment, so its pods are not joined
+	dm := health[1]
+	if dm.Available != 0 || dm.Restarts != 0 || dm.Created != nil {
+		t.Errorf("expected no health for device-management, got %+v", dm)
+	}
+
+	summary := summarizeHealth(health)
+	if summary.Microservices != 2 || summary.ReadyMicroservices != 0 {
+		t.Errorf("expected 0/2 ready microservices, got %d/%d", summary.ReadyMicroservices, summary.Microservices)
+	}
+	if summary.Available != 1 || summary.Restarts != 5 {
+		t.Errorf("expected 1 available and 5 restarts, got %d and %d", summary.Available, summary.Restarts)
+	}
+}
+
+func TestMicroserviceHealthWaitingReason(t *testing.T) {
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "device-management", Namespace: "sitewhere"},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "device-management"}},
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Image: "docker.io/sitewhere/service-device-management:3.0.6"}},
+			}},
+		},
+	}
+	ms := rollingMicroservice("device-management")
+	pod := healthPod("device-management-1", "device-management", "node-a", corev1.ContainerStatus{
+		Image:        "docker.io/sitewhere/service-device-management:3.0.4",
+		RestartCount: 7,
+		State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		LastTerminationState: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{Reason: "Error", FinishedAt: metav1.Now()},
+		},
+	})
+	health := microserviceHealth(&ms, deploy, []corev1.Pod{*pod})
+	if health.LastTerminationReason != "CrashLoopBackOff" {
+		t.Errorf("expected CrashLoopBackOff, got %s", health.LastTerminationReason)
+	}
+	if len(health.Tags) != 1 || health.Tags[0] != "3.0.4" {
+		t.Errorf("expected running tag 3.0.4, got %v", health.Tags)
+	}
+
+	health = microserviceHealth(&ms, deploy, nil)
+	if len(health.Tags) != 1 || health.Tags[0] != "3.0.6" {
+		t.Errorf("expected template tag 3.0.6, got %v", health.Tags)
+	}
+}
+
+func TestImageTag(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		image    string
+		expected string
+	}{
+		{image: "docker.io/sitewhere/service-event-sources:3.0.5", expected: "3.0.5"},
+		{image: "localhost:5000/sitewhere/service-event-sources", expected: "latest"},
+		{image: "localhost:5000/sitewhere/service-event-sources:3.0.5", expected: "3.0.5"},
+		{image: "sitewhere/service-event-sources:3.0.5@sha256:abcdef", expected: "3.0.5"},
+	}
+	for _, single := range data {
+		t.Run(single.image, func(single struct {
+			image    string
+			expected string
+		}) func(t *testing.T) {
+			return func(t *testing.T) {
+				t.Parallel()
+				if got := imageTag(single.image); got != single.expected {
+					t.Errorf("expected %s, got %s", single.expected, got)
+				}
+			}
+		}(single))
+	}
+}
+
+func TestInstanceSummaryForbidden(t *testing.T) {
+	client := ctlfake.NewFakeClientWithScheme(scheme)
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("list", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(appsv1.Resource("deployments"), "", nil)
+	})
+	summary := instanceSummary(context.TODO(), client, clientset, "sitewhere")
+	if summary.Error == "" {
+		t.Fatalf("expected the summary to report the error")
+	}
+}
@@ -0,0 +1,112 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	ctlcli "sigs.k8s.io/controller-runtime/pkg/client"
+
+	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
+
+	"helm.sh/helm/v3/pkg/action"
+)
+
+// getInstance finds a SiteWhere instance by name and resolves the
+// namespace of its resources
+func getInstance(ctx context.Context, cfg *action.Configuration, client ctlcli.Client, name string) (*sitewhereiov1alpha4.SiteWhereInstance, string, error) {
+	var swInstance sitewhereiov1alpha4.SiteWhereInstance
+	if err := client.Get(ctx, ctlcli.ObjectKey{Name: name}, &swInstance); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, "", fmt.Errorf("sitewhere instance '%s' not found", name)
+		}
+		return nil, "", err
+	}
+	clientset, err := cfg.KubernetesClientSet()
+	if err != nil {
+		return nil, "", err
+	}
+	namespaces, err := instanceNamespaces(ctx, clientset, []sitewhereiov1alpha4.SiteWhereInstance{swInstance})
+	if err != nil {
+		return nil, "", err
+	}
+	return &swInstance, namespaces[name], nil
+}
+
+// instanceNamespaces resolves the namespace of each instance, by instance name
+func instanceNamespaces(ctx context.Context, clientset kubernetes.Interface, instances []sitewhereiov1alpha4.SiteWhereInstance) (map[string]string, error) {
+	items, err := listNamespaces(ctx, clientset)
+	if err != nil {
+		return nil, err
+	}
+	var result = map[string]string{}
+	for i := range instances {
+		result[instances[i].GetName()] = resolveInstanceNamespace(&instances[i], items)
+	}
+	return result, nil
+}
+
+// listNamespaces lists the namespaces of the cluster. A user who cannot
+// list them gets an empty list.
+func listNamespaces(ctx context.Context, clientset kubernetes.Interface) ([]corev1.Namespace, error) {
+	namespaceList, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if apierrors.IsForbidden(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return namespaceList.Items, nil
+}
+
+// resolveInstanceNamespace returns the namespace controlled by the instance,
+// or the instance name, which is the namespace the operator creates
+func resolveInstanceNamespace(swInstance *sitewhereiov1alpha4.SiteWhereInstance, namespaces []corev1.Namespace) string {
+	if namespace, ok := findControlledNamespace(swInstance, namespaces); ok {
+		return namespace
+	}
+	return swInstance.GetName()
+}
+
+// findControlledNamespace returns the namespace whose controller is the instance
+func findControlledNamespace(swInstance *sitewhereiov1alpha4.SiteWhereInstance, namespaces []corev1.Namespace) (string, bool) {
+	for _, ns := range namespaces {
+		owner := metav1.GetControllerOf(&ns)
+		if owner != nil && owner.Kind == sitewhereiov1alpha4.SiteWhereInstanceKind &&
+			owner.Name == swInstance.GetName() && owner.UID == swInstance.GetUID() {
+			return ns.GetName(), true
+		}
+	}
+	return "", false
+}
+
+// instanceControlledNamespace returns the namespace created by the operator for the
+// instance, or an empty string if there is none. Only this namespace can be
+// purged with the instance.
+func instanceControlledNamespace(ctx context.Context, clientset kubernetes.Interface, swInstance *sitewhereiov1alpha4.SiteWhereInstance) (string, error) {
+	namespaceList, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+	namespace, _ := findControlledNamespace(swInstance, namespaceList.Items)
+	return namespace, nil
+}
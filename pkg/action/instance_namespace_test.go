@@ -0,0 +1,116 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
+)
+
+func controlledNamespace(name string, instance string, uid types.UID) *corev1.Namespace {
+	controller := true
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: sitewhereiov1alpha4.GroupVersion.String(),
+					Kind:       sitewhereiov1alpha4.SiteWhereInstanceKind,
+					Name:       instance,
+					UID:        uid,
+					Controller: &controller,
+				},
+			},
+		},
+	}
+}
+
+func TestInstanceNamespaces(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		controlledNamespace("iot-prod", "prod", "uid-prod"),
+		controlledNamespace("old-staging", "staging", "uid-old"),
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "sitewhere"}},
+	)
+	instances := []sitewhereiov1alpha4.SiteWhereInstance{
+		{ObjectMeta: metav1.ObjectMeta{Name: "prod", UID: "uid-prod"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "staging", UID: "uid-new", Annotations: map[string]string{"sitewhere.io/namespace": "iot-staging"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "sitewhere", UID: "uid-sitewhere"}},
+	}
+	namespaces, err := instanceNamespaces(context.TODO(), clientset, instances)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{
+		"prod":      "iot-prod",
+		"staging":   "staging",
+		"sitewhere": "sitewhere",
+	}
+	for name, namespace := range expected {
+		if namespaces[name] != namespace {
+			t.Fatalf("expected namespace %s for instance %s, got %s", namespace, name, namespaces[name])
+		}
+	}
+}
+
+func TestInstanceControlledNamespace(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		controlledNamespace("iot-prod", "prod", "uid-prod"),
+		controlledNamespace("staging", "staging", "uid-old"),
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "sitewhere"}},
+	)
+	data := []struct {
+		instance sitewhereiov1alpha4.SiteWhereInstance
+		expected string
+	}{
+		{instance: sitewhereiov1alpha4.SiteWhereInstance{ObjectMeta: metav1.ObjectMeta{Name: "prod", UID: "uid-prod"}}, expected: "iot-prod"},
+		{instance: sitewhereiov1alpha4.SiteWhereInstance{ObjectMeta: metav1.ObjectMeta{Name: "staging", UID: "uid-new"}}, expected: ""},
+		{instance: sitewhereiov1alpha4.SiteWhereInstance{ObjectMeta: metav1.ObjectMeta{Name: "sitewhere", UID: "uid-sitewhere"}}, expected: ""},
+	}
+	for _, single := range data {
+		namespace, err := instanceControlledNamespace(context.TODO(), clientset, &single.instance)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if namespace != single.expected {
+			t.Fatalf("expected namespace '%s' for instance %s, got '%s'", single.expected, single.instance.GetName(), namespace)
+		}
+	}
+}
+
+func TestListNamespacesForbidden(t *testing.T) {
+	clientset := fake.NewSimpleClientset(controlledNamespace("sitewhere", "sitewhere", "uid-1"))
+	clientset.PrependReactor("list", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(corev1.Resource("namespaces"), "", nil)
+	})
+	namespaces, err := listNamespaces(context.TODO(), clientset)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(namespaces) != 0 {
+		t.Fatalf("expected no namespaces, got %d", len(namespaces))
+	}
+}
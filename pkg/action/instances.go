@@ -18,13 +18,11 @@ package action
 
 import (
 	"context"
-	"fmt"
 	"strings"
 
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
-
 	"github.com/pkg/errors"
 	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
+	"k8s.io/client-go/kubernetes"
 	ctlcli "sigs.k8s.io/controller-runtime/pkg/client"
 
 	"helm.sh/helm/v3/pkg/action"
@@ -74,34 +72,73 @@ func (i *Instances) instancesDetails(ctx context.Context, client ctlcli.Client)
 	if err != nil {
 		return nil, err
 	}
+	clientset, err := i.cfg.KubernetesClientSet()
+	if err != nil {
+		return nil, err
+	}
+	namespaces, err := instanceNamespaces(ctx, clientset, swInstancesList.Items)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make(map[string]instance.InstanceSummary, len(swInstancesList.Items))
+	for _, swInstance := range swInstancesList.Items {
+		namespace, ok := namespaces[swInstance.GetName()]
+		if !ok {
+			continue
+		}
+		summaries[swInstance.GetName()] = instanceSummary(ctx, client, clientset, namespace)
+	}
 	return &instance.ListSiteWhereInstance{
-		Instances: swInstancesList.Items,
+		Instances:  swInstancesList.Items,
+		Namespaces: namespaces,
+		Summaries:  summaries,
 	}, nil
 }
 
+// instanceSummary returns the health summary of the microservices in the
+// namespace. The summary is best effort, a user who can only read the
+// instances gets a summary with the error.
+func instanceSummary(ctx context.Context, client ctlcli.Client, clientset kubernetes.Interface, namespace string) instance.InstanceSummary {
+	var swMicroservoceList sitewhereiov1alpha4.SiteWhereMicroserviceList
+	if err := client.List(ctx, &swMicroservoceList, ctlcli.InNamespace(namespace)); err != nil {
+		return instance.InstanceSummary{Error: err.Error()}
+	}
+	health, err := microservicesHealth(ctx, clientset, namespace, swMicroservoceList.Items)
+	if err != nil {
+		return instance.InstanceSummary{Error: err.Error()}
+	}
+	return summarizeHealth(health)
+}
+
 func (i *Instances) singelInstanceDetail(ctx context.Context, client ctlcli.Client) (*instance.ListSiteWhereInstance, error) {
 	var err error
 
-	var swInstanceCR sitewhereiov1alpha4.SiteWhereInstance
-	err = client.Get(ctx, ctlcli.ObjectKey{Name: i.InstanceName}, &swInstanceCR)
+	swInstanceCR, namespace, err := getInstance(ctx, i.cfg, client, i.InstanceName)
 	if err != nil {
-		if apierrors.IsNotFound(err) {
-			return nil, fmt.Errorf("sitewhere instance '%s' not found", i.InstanceName)
-		}
 		return nil, err
 	}
 
 	var swMicroservoceList sitewhereiov1alpha4.SiteWhereMicroserviceList
-	err = client.List(ctx, &swMicroservoceList, ctlcli.InNamespace(i.InstanceName))
+	err = client.List(ctx, &swMicroservoceList, ctlcli.InNamespace(namespace))
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := i.cfg.KubernetesClientSet()
+	if err != nil {
+		return nil, err
+	}
+	health, err := microservicesHealth(ctx, clientset, namespace, swMicroservoceList.Items)
 	if err != nil {
 		return nil, err
 	}
 
 	return &instance.ListSiteWhereInstance{
 		Instances: []sitewhereiov1alpha4.SiteWhereInstance{
-			swInstanceCR,
+			*swInstanceCR,
 		},
+		Namespaces:    map[string]string{i.InstanceName: namespace},
 		Microservices: swMicroservoceList.Items,
+		Health:        health,
 	}, nil
 }
 
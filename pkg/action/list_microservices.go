@@ -18,11 +18,9 @@ package action
 
 import (
 	"context"
-	"fmt"
 
 	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
 	"helm.sh/helm/v3/pkg/action"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	ctlcli "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -39,6 +37,9 @@ type ListMicroservicesResult struct {
 	// Name of the instance
 	Name string `json:"name"`
 
+	// Namespace of the instance
+	Namespace string `json:"namespace"`
+
 	// Microservices are the microservices of a instance
 	Microservices []sitewhereiov1alpha4.SiteWhereMicroservice `json:"microservices"`
 }
@@ -64,23 +65,20 @@ func (i *ListMicroservices) Run() (*ListMicroservicesResult, error) {
 	}
 	ctx := context.TODO()
 
-	var swInstanceCR sitewhereiov1alpha4.SiteWhereInstance
-	err = client.Get(ctx, ctlcli.ObjectKey{Name: i.InstanceName}, &swInstanceCR)
+	_, namespace, err := getInstance(ctx, i.cfg, client, i.InstanceName)
 	if err != nil {
-		if apierrors.IsNotFound(err) {
-			return nil, fmt.Errorf("sitewhere instance '%s' not found", i.InstanceName)
-		}
 		return nil, err
 	}
 
 	var swMicroservoceList sitewhereiov1alpha4.SiteWhereMicroserviceList
-	err = client.List(ctx, &swMicroservoceList, ctlcli.InNamespace(i.InstanceName))
+	err = client.List(ctx, &swMicroservoceList, ctlcli.InNamespace(namespace))
 	if err != nil {
 		return nil, err
 	}
 
 	return &ListMicroservicesResult{
 		Name:          i.InstanceName,
+		Namespace:     namespace,
 		Microservices: swMicroservoceList.Items,
 	}, nil
 }
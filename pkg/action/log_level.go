@@ -72,19 +72,15 @@ func (i *LogLevel) Run() error {
 	var ctx = context.TODO()
 
 	// Find the SiteWhere Instance
-	var swInstanceCR sitewhereiov1alpha4.SiteWhereInstance
-	err = controllerClient.Get(ctx, ctlcli.ObjectKey{Name: i.InstanceName}, &swInstanceCR)
+	_, namespace, err := getInstance(ctx, i.cfg, controllerClient, i.InstanceName)
 	if err != nil {
-		if apierrors.IsNotFound(err) {
-			return fmt.Errorf("sitewhere instance '%s' not found", i.InstanceName)
-		}
 		return err
 	}
 
 	// Find the SiteWhere Microservice
 	var swMicroserviceCR sitewhereiov1alpha4.SiteWhereMicroservice
 	var objectKey ctlcli.ObjectKey = ctlcli.ObjectKey{
-		Namespace: i.InstanceName,
+		Namespace: namespace,
 		Name:      i.MicroserviceName,
 	}
 
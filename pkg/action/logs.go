@@ -19,20 +19,16 @@ package action
 import (
 	"bufio"
 	"context"
-	"fmt"
 	"io"
 	"os"
 
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 
-	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
 	"helm.sh/helm/v3/pkg/action"
 	"k8s.io/client-go/kubernetes"
-	ctlcli "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // Logs is the action for showing SiteWhere Microservoce Logs
@@ -74,59 +70,18 @@ func (i *Logs) Run() error {
 
 	var ctx = context.TODO()
 
-	// Find the SiteWhere Instance
-	var swInstanceCR sitewhereiov1alpha4.SiteWhereInstance
-	err = controllerClient.Get(ctx, ctlcli.ObjectKey{Name: i.InstanceName}, &swInstanceCR)
+	// Find the SiteWhere Microservice and its Pod
+	swMicroserviceCR, pod, err := microservicePod(ctx, i.cfg, controllerClient, i.InstanceName, i.MicroserviceName, false)
 	if err != nil {
-		if apierrors.IsNotFound(err) {
-			return fmt.Errorf("sitewhere instance '%s' not found", i.InstanceName)
-		}
-		return err
-	}
-
-	// Find the SiteWhere Microservice
-	var swMicroserviceCR sitewhereiov1alpha4.SiteWhereMicroservice
-	var objectKey ctlcli.ObjectKey = ctlcli.ObjectKey{
-		Namespace: i.InstanceName,
-		Name:      i.MicroserviceName,
-	}
-
-	err = controllerClient.Get(ctx, objectKey, &swMicroserviceCR)
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			return fmt.Errorf("the Microservice %s for Instance %s does not exists", i.MicroserviceName, i.InstanceName)
-		}
 		return err
 	}
 
-	var namespace = swInstanceCR.GetName()
-	var deployName = swMicroserviceCR.Status.Deployment
-	var containerName = swMicroserviceCR.GetName()
-	var podName = ""
-
 	clientset, err := i.cfg.KubernetesClientSet()
 	if err != nil {
 		return err
 	}
 
-	deploy, err := clientset.AppsV1().Deployments(namespace).Get(ctx, deployName, metav1.GetOptions{})
-	if err != nil {
-		return err
-	}
-
-	podList, err := getPodsForDeployment(ctx, clientset, deploy)
-	if err != nil {
-		return err
-	}
-
-	if len(podList.Items) <= 0 {
-		return fmt.Errorf("no Pods of Microservice %s for Instance %s were found", i.MicroserviceName, i.InstanceName)
-	}
-
-	// For now, let's choose the 1st Pod
-	podName = podList.Items[0].GetName()
-
-	return getPodLogs(clientset, namespace, podName, containerName, i.Follow, os.Stdout)
+	return getPodLogs(clientset, pod.GetNamespace(), pod.GetName(), swMicroserviceCR.GetName(), i.Follow, os.Stdout)
 }
 
 func getPodsForDeployment(ctx context.Context, clientset kubernetes.Interface, deploy *appsv1.Deployment) (*v1.PodList, error) {
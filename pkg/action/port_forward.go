@@ -0,0 +1,250 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+	ctlcli "sigs.k8s.io/controller-runtime/pkg/client"
+
+	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
+
+	"helm.sh/helm/v3/pkg/action"
+)
+
+// instanceManagementArea is the functional area of the microservice with
+// the REST and gRPC APIs of the instance
+const instanceManagementArea = "instance-management"
+
+// PortForward is the action for forwarding local ports to the ports of a
+// SiteWhere Microservice
+type PortForward struct {
+	cfg *action.Configuration
+
+	// Name of the Instance
+	InstanceName string
+	// Name of the Microservice in the instance
+	MicroserviceName string
+	// Ports to forward, as [LOCAL:]REMOTE where REMOTE is the name or the
+	// number of a port of the microservice service. Every port if empty.
+	Ports []string
+	// Addresses to listen on
+	Addresses []string
+	// StopCh stops the port forwarding when closed
+	StopCh <-chan struct{}
+	// ReadyCh is closed when the ports are forwarded
+	ReadyCh chan struct{}
+	// Out receives the forwarded ports
+	Out io.Writer
+}
+
+// NewPortForward constructs a new *PortForward
+func NewPortForward(cfg *action.Configuration) *PortForward {
+	return &PortForward{
+		cfg:              cfg,
+		InstanceName:     "",
+		MicroserviceName: instanceManagementArea,
+		Ports:            nil,
+		Addresses:        []string{"localhost"},
+		StopCh:           nil,
+		ReadyCh:          nil,
+		Out:              os.Stdout,
+	}
+}
+
+// Run forwards the ports of the microservice until StopCh is closed
+func (i *PortForward) Run() error {
+	if err := i.cfg.KubeClient.IsReachable(); err != nil {
+		return err
+	}
+	client, err := ControllerClient(i.cfg)
+	if err != nil {
+		return err
+	}
+	swMicroservice, pod, err := microservicePod(context.TODO(), i.cfg, client, i.InstanceName, i.MicroserviceName, true)
+	if err != nil {
+		return err
+	}
+	var servicePorts []v1.ServicePort
+	if swMicroservice.Spec.SerivceSpec != nil {
+		servicePorts = swMicroservice.Spec.SerivceSpec.Ports
+	}
+	ports, err := resolvePorts(i.Ports, servicePorts, pod)
+	if err != nil {
+		return err
+	}
+	if len(ports) == 0 {
+		return fmt.Errorf("the Microservice %s for Instance %s has no ports", i.MicroserviceName, i.InstanceName)
+	}
+	return forwardPorts(i.cfg, pod, i.Addresses, ports, i.StopCh, i.ReadyCh, i.Out, i.Out)
+}
+
+// resolvePorts converts [LOCAL:]REMOTE ports, where REMOTE is the name or the
+// number of a service port, to the LOCAL:CONTAINER ports of the pod. Without
+// ports, every service port is forwarded to the same local port.
+func resolvePorts(ports []string, servicePorts []v1.ServicePort, pod *v1.Pod) ([]string, error) {
+	if len(ports) == 0 {
+		for _, servicePort := range servicePorts {
+			ports = append(ports, strconv.Itoa(int(servicePort.Port)))
+		}
+	}
+	var result []string
+	for _, port := range ports {
+		var local, remote = "", port
+		if idx := strings.Index(port, ":"); idx >= 0 {
+			local, remote = port[:idx], port[idx+1:]
+		}
+		servicePort := findServicePort(servicePorts, remote)
+		if servicePort == nil {
+			number, err := strconv.Atoi(remote)
+			if err != nil {
+				return nil, fmt.Errorf("unknown port '%s'", remote)
+			}
+			servicePort = &v1.ServicePort{Port: int32(number)}
+		}
+		target, err := targetPort(servicePort, pod)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.Contains(port, ":") {
+			local = strconv.Itoa(int(servicePort.Port))
+		}
+		result = append(result, fmt.Sprintf("%s:%d", local, target))
+	}
+	return result, nil
+}
+
+// findServicePort finds a service port by name or by number
+func findServicePort(servicePorts []v1.ServicePort, port string) *v1.ServicePort {
+	for i := range servicePorts {
+		if servicePorts[i].Name == port || strconv.Itoa(int(servicePorts[i].Port)) == port {
+			return &servicePorts[i]
+		}
+	}
+	return nil
+}
+
+// targetPort returns the container port of a service port
+func targetPort(servicePort *v1.ServicePort, pod *v1.Pod) (int32, error) {
+	if servicePort.TargetPort.Type == intstr.String && servicePort.TargetPort.StrVal != "" {
+		for _, container := range pod.Spec.Containers {
+			for _, containerPort := range container.Ports {
+				if containerPort.Name == servicePort.TargetPort.StrVal {
+					return containerPort.ContainerPort, nil
+				}
+			}
+		}
+		return 0, fmt.Errorf("pod %s has no port named '%s'", pod.GetName(), servicePort.TargetPort.StrVal)
+	}
+	if servicePort.TargetPort.IntVal > 0 {
+		return servicePort.TargetPort.IntVal, nil
+	}
+	return servicePort.Port, nil
+}
+
+// microservicePod finds a microservice of an instance and the pod used to
+// reach it, the first running pod of its deployment. If running is false and
+// no pod is running, the first pod is used.
+func microservicePod(ctx context.Context, cfg *action.Configuration, client ctlcli.Client, instanceName string, microserviceName string, running bool) (*sitewhereiov1alpha4.SiteWhereMicroservice, *v1.Pod, error) {
+	_, namespace, err := getInstance(ctx, cfg, client, instanceName)
+	if err != nil {
+		return nil, nil, err
+	}
+	var swMicroservice sitewhereiov1alpha4.SiteWhereMicroservice
+	var objectKey = ctlcli.ObjectKey{
+		Namespace: namespace,
+		Name:      microserviceName,
+	}
+	if err := client.Get(ctx, objectKey, &swMicroservice); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil, fmt.Errorf("the Microservice %s for Instance %s does not exists", microserviceName, instanceName)
+		}
+		return nil, nil, err
+	}
+	clientset, err := cfg.KubernetesClientSet()
+	if err != nil {
+		return nil, nil, err
+	}
+	deploy, err := clientset.AppsV1().Deployments(namespace).Get(ctx, swMicroservice.Status.Deployment, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	podList, err := getPodsForDeployment(ctx, clientset, deploy)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(podList.Items) <= 0 {
+		return nil, nil, fmt.Errorf("no Pods of Microservice %s for Instance %s were found", microserviceName, instanceName)
+	}
+	if pod := runningPod(podList.Items); pod != nil {
+		return &swMicroservice, pod, nil
+	}
+	if running {
+		return nil, nil, fmt.Errorf("no running Pod of Microservice %s for Instance %s was found", microserviceName, instanceName)
+	}
+	return &swMicroservice, &podList.Items[0], nil
+}
+
+// runningPod returns the first running pod, nil if there is none
+func runningPod(pods []v1.Pod) *v1.Pod {
+	for i := range pods {
+		if pods[i].Status.Phase == v1.PodRunning {
+			return &pods[i]
+		}
+	}
+	return nil
+}
+
+// forwardPorts forwards local ports to the ports of a pod until stopCh is
+// closed. Ports are in the LOCAL:REMOTE format of kubectl port-forward.
+func forwardPorts(cfg *action.Configuration, pod *v1.Pod, addresses []string, ports []string, stopCh <-chan struct{}, readyCh chan struct{}, out io.Writer, errOut io.Writer) error {
+	restConfig, err := cfg.RESTClientGetter.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	clientset, err := cfg.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+	transport, upgrader, err := spdy.RoundTripperFor(restConfig)
+	if err != nil {
+		return err
+	}
+	req := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.GetNamespace()).
+		Name(pod.GetName()).
+		SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+	fw, err := portforward.NewOnAddresses(dialer, addresses, ports, stopCh, readyCh, out, errOut)
+	if err != nil {
+		return err
+	}
+	return fw.ForwardPorts()
+}
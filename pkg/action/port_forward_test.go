@@ -0,0 +1,95 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestResolvePorts(t *testing.T) {
+	t.Parallel()
+	servicePorts := []v1.ServicePort{
+		{Name: "grpc-api", Port: 9000, TargetPort: intstr.FromInt(9000)},
+		{Name: "http-metrics", Port: 9090, TargetPort: intstr.FromString("metrics")},
+		{Name: "http-rest", Port: 80, TargetPort: intstr.FromInt(8080)},
+	}
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{Ports: []v1.ContainerPort{{Name: "metrics", ContainerPort: 19090}}},
+			},
+		},
+	}
+	data := []struct {
+		name     string
+		ports    []string
+		expected []string
+		err      bool
+	}{
+		{name: "all-ports", expected: []string{"9000:9000", "9090:19090", "80:8080"}},
+		{name: "by-name", ports: []string{"http-rest", "8081:grpc-api"}, expected: []string{"80:8080", "8081:9000"}},
+		{name: "random-local-port", ports: []string{":http-metrics"}, expected: []string{":19090"}},
+		{name: "by-number", ports: []string{"80", "7000"}, expected: []string{"80:8080", "7000:7000"}},
+		{name: "unknown-name", ports: []string{"http-unknown"}, err: true},
+	}
+	for _, single := range data {
+		t.Run(single.name, func(single struct {
+			name     string
+			ports    []string
+			expected []string
+			err      bool
+		}) func(t *testing.T) {
+			return func(t *testing.T) {
+				result, err := resolvePorts(single.ports, servicePorts, pod)
+				if single.err {
+					if err == nil {
+						t.Fatalf("expected error")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf(err.Error())
+				}
+				if len(result) != len(single.expected) {
+					t.Fatalf("expected %v, got %v", single.expected, result)
+				}
+				for i := range result {
+					if result[i] != single.expected[i] {
+						t.Fatalf("expected %v, got %v", single.expected, result)
+					}
+				}
+			}
+		}(single))
+	}
+}
+
+func TestRunningPod(t *testing.T) {
+	pods := []v1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "pending"}, Status: v1.PodStatus{Phase: v1.PodPending}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "running"}, Status: v1.PodStatus{Phase: v1.PodRunning}},
+	}
+	if pod := runningPod(pods); pod == nil || pod.GetName() != "running" {
+		t.Fatalf("expected the running pod, got %v", pod)
+	}
+	if pod := runningPod(pods[:1]); pod != nil {
+		t.Fatalf("expected no running pod, got %s", pod.GetName())
+	}
+}
@@ -0,0 +1,87 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/sitewhere/swctl/pkg/install/profile"
+	"github.com/sitewhere/swctl/pkg/preflight"
+
+	"helm.sh/helm/v3/pkg/action"
+)
+
+// Preflight is the action for running the pre-flight checks
+type Preflight struct {
+	cfg *action.Configuration
+	// StorageClass is the storage class requested for the infrastructure
+	StorageClass string
+	// Minimal check the requirements of the minimal profile
+	Minimal bool
+	// Instance run the checks for instance creation instead of install
+	Instance bool
+}
+
+// NewPreflight constructs a new *Preflight
+func NewPreflight(cfg *action.Configuration) *Preflight {
+	return &Preflight{
+		cfg:          cfg,
+		StorageClass: "",
+		Minimal:      false,
+		Instance:     false,
+	}
+}
+
+// Run executes the pre-flight checks and returns the report
+func (p *Preflight) Run() (*preflight.Report, error) {
+	checks := preflight.InstallChecks()
+	if p.Instance {
+		checks = preflight.InstanceChecks()
+	}
+	return runPreflight(p.cfg, checks, p.StorageClass, p.Minimal)
+}
+
+func runPreflight(cfg *action.Configuration, checks []preflight.Check, storageClass string, minimal bool) (*preflight.Report, error) {
+	if err := cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	clientset, err := cfg.KubernetesClientSet()
+	if err != nil {
+		return nil, err
+	}
+	var prof profile.SiteWhereProfile = profile.Default
+	if minimal {
+		prof = profile.Minimal
+	}
+	c := &preflight.Context{
+		Clientset:    clientset,
+		StorageClass: storageClass,
+		Profile:      prof,
+	}
+	return preflight.Run(context.TODO(), checks, c), nil
+}
+
+// writePreflightWarnings writes the warnings of the report to out
+func writePreflightWarnings(out io.Writer, report *preflight.Report) {
+	for _, result := range report.Results {
+		if result.Result == preflight.Warn {
+			fmt.Fprintf(out, "Warning: %s: %s\n", result.Name, result.Message)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sitewhere/swctl/pkg/cli"
+	"github.com/sitewhere/swctl/pkg/install"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// defaultHistoryMax is the default number of revisions shown
+const defaultHistoryMax = 10
+
+// ReleaseStatus is the action for showing the installed SiteWhere release
+type ReleaseStatus struct {
+	cfg *action.Configuration
+
+	settings *cli.EnvSettings
+
+	// Max is the maximum number of revisions in the history
+	Max int
+	// Use verbose mode
+	Verbose bool
+}
+
+// NewReleaseStatus constructs a new *ReleaseStatus
+func NewReleaseStatus(cfg *action.Configuration, settings *cli.EnvSettings) *ReleaseStatus {
+	return &ReleaseStatus{
+		cfg:      cfg,
+		settings: settings,
+		Max:      defaultHistoryMax,
+		Verbose:  false,
+	}
+}
+
+// Run executes the status command, returning the status and history of the release.
+func (i *ReleaseStatus) Run() (*install.SiteWhereReleaseStatus, error) {
+	if err := i.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	actionConfig, err := newReleaseConfiguration(i.settings, i.Verbose)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := action.NewStatus(actionConfig).Run(i.settings.ReleaseName)
+	if err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			return nil, errors.Errorf("release %s not found, install SiteWhere with `swctl install` first", i.settings.ReleaseName)
+		}
+		return nil, err
+	}
+	historyAction := action.NewHistory(actionConfig)
+	historyAction.Max = i.Max
+	history, err := historyAction.Run(i.settings.ReleaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := i.cfg.KubernetesClientSet()
+	if err != nil {
+		return nil, err
+	}
+	extensionsClient, err := KubernetesAPIExtensionClientSet(i.cfg)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.TODO()
+	operatorImage, err := findOperatorImage(ctx, clientset, i.settings.SystemNamespace)
+	if err != nil {
+		return nil, err
+	}
+	crds, err := listCRDVersions(ctx, extensionsClient)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &install.SiteWhereReleaseStatus{
+		Release:       rel.Name,
+		Namespace:     rel.Namespace,
+		Revision:      rel.Version,
+		OperatorImage: operatorImage,
+		CRDs:          crds,
+		History:       releaseRevisions(history, i.Max),
+	}
+	if rel.Info != nil {
+		result.Status = rel.Info.Status.String()
+		result.LastDeployed = rel.Info.LastDeployed.Time
+		result.Notes = rel.Info.Notes
+	}
+	if rel.Chart != nil && rel.Chart.Metadata != nil {
+		result.ChartVersion = rel.Chart.Metadata.Version
+		result.AppVersion = rel.Chart.Metadata.AppVersion
+	}
+	return result, nil
+}
+
+// releaseRevisions returns at most max revisions of the release, the newest first
+func releaseRevisions(history []*release.Release, max int) []install.ReleaseRevision {
+	var revisions []install.ReleaseRevision
+	for _, rel := range history {
+		revision := install.ReleaseRevision{Revision: rel.Version}
+		if rel.Info != nil {
+			revision.Updated = rel.Info.LastDeployed.Time
+			revision.Status = rel.Info.Status.String()
+			revision.Description = rel.Info.Description
+		}
+		if rel.Chart != nil && rel.Chart.Metadata != nil {
+			revision.Chart = rel.Chart.Metadata.Name + "-" + rel.Chart.Metadata.Version
+			revision.AppVersion = rel.Chart.Metadata.AppVersion
+		}
+		revisions = append(revisions, revision)
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision > revisions[j].Revision
+	})
+	if max > 0 && len(revisions) > max {
+		revisions = revisions[:max]
+	}
+	return revisions
+}
+
+// findOperatorImage returns the image of the SiteWhere Operator Deployment,
+// or an empty string if it is not found
+func findOperatorImage(ctx context.Context, clientset kubernetes.Interface, namespace string) (string, error) {
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+	for _, deploy := range deployments.Items {
+		if !isOperatorDeployment(&deploy) {
+			continue
+		}
+		for _, container := range deploy.Spec.Template.Spec.Containers {
+			if strings.Contains(container.Name, "operator") || len(deploy.Spec.Template.Spec.Containers) == 1 {
+				return container.Image, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// listCRDVersions returns the versions of the CRDs of sitewhere.io groups
+func listCRDVersions(ctx context.Context, extensionsClient clientset.Interface) ([]install.CRDVersion, error) {
+	crdList, err := extensionsClient.ApiextensionsV1().CustomResourceDefinitions().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var result []install.CRDVersion
+	for _, crd := range crdList.Items {
+		if !isSiteWhereCRDGroup(crd.Spec.Group) {
+			continue
+		}
+		for _, version := range crd.Spec.Versions {
+			result = append(result, install.CRDVersion{
+				Name:    crd.GetName(),
+				Version: version.Name,
+				Served:  version.Served,
+				Storage: version.Storage,
+			})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
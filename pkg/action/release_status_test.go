@@ -0,0 +1,149 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	extensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestReleaseRevisions(t *testing.T) {
+	t.Parallel()
+	var history []*release.Release
+	for version := 1; version <= 3; version++ {
+		history = append(history, &release.Release{
+			Name:    "sitewhere",
+			Version: version,
+			Info:    &release.Info{Status: release.StatusSuperseded},
+			Chart:   &chart.Chart{Metadata: &chart.Metadata{Name: "sitewhere-infrastructure", Version: "0.1.13", AppVersion: "3.0.0"}},
+		})
+	}
+	data := []struct {
+		name     string
+		max      int
+		expected []int
+	}{
+		{
+			name:     "all",
+			max:      0,
+			expected: []int{3, 2, 1},
+		},
+		{
+			name:     "max",
+			max:      2,
+			expected: []int{3, 2},
+		},
+	}
+	for _, single := range data {
+		t.Run(single.name, func(single struct {
+			name     string
+			max      int
+			expected []int
+		}) func(t *testing.T) {
+			return func(t *testing.T) {
+				revisions := releaseRevisions(history, single.max)
+				if len(revisions) != len(single.expected) {
+					t.Fatalf("expected %d revisions, got %d", len(single.expected), len(revisions))
+				}
+				for i, revision := range revisions {
+					if revision.Revision != single.expected[i] {
+						t.Fatalf("expected revision %d, got %d", single.expected[i], revision.Revision)
+					}
+					if revision.Chart != "sitewhere-infrastructure-0.1.13" || revision.AppVersion != "3.0.0" {
+						t.Fatalf("unexpected chart %s %s", revision.Chart, revision.AppVersion)
+					}
+				}
+			}
+		}(single))
+	}
+}
+
+func TestFindOperatorImage(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "sitewhere-keycloak", Namespace: "sitewhere-system"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "keycloak", Image: "jboss/keycloak:11.0.3"}},
+			}}},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "sitewhere-operator", Namespace: "sitewhere-system"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "kube-rbac-proxy", Image: "gcr.io/kubebuilder/kube-rbac-proxy:v0.5.0"},
+					{Name: "sitewhere-operator", Image: "docker.io/sitewhere/sitewhere-k8s-operator:0.1.2"},
+				},
+			}}},
+		},
+	)
+	image, err := findOperatorImage(context.TODO(), clientset, "sitewhere-system")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if image != "docker.io/sitewhere/sitewhere-k8s-operator:0.1.2" {
+		t.Fatalf("unexpected operator image %s", image)
+	}
+}
+
+func TestListCRDVersions(t *testing.T) {
+	extensionsClient := extensionsfake.NewSimpleClientset(
+		&apiextv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "tenants.sitewhere.io"},
+			Spec: apiextv1.CustomResourceDefinitionSpec{
+				Group:    "sitewhere.io",
+				Versions: []apiextv1.CustomResourceDefinitionVersion{{Name: "v1alpha4", Served: true, Storage: true}},
+			},
+		},
+		&apiextv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "instances.sitewhere.io"},
+			Spec: apiextv1.CustomResourceDefinitionSpec{
+				Group: "sitewhere.io",
+				Versions: []apiextv1.CustomResourceDefinitionVersion{
+					{Name: "v1alpha3", Served: true},
+					{Name: "v1alpha4", Served: true, Storage: true},
+				},
+			},
+		},
+		&apiextv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "kafkas.kafka.strimzi.io"},
+			Spec:       apiextv1.CustomResourceDefinitionSpec{Group: "kafka.strimzi.io"},
+		},
+	)
+	versions, err := listCRDVersions(context.TODO(), extensionsClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(versions) != 3 {
+		t.Fatalf("expected 3 versions, got %v", versions)
+	}
+	if versions[0].Name != "instances.sitewhere.io" || versions[0].Version != "v1alpha3" || versions[0].Storage {
+		t.Fatalf("unexpected version %v", versions[0])
+	}
+	if versions[2].Name != "tenants.sitewhere.io" || !versions[2].Storage {
+		t.Fatalf("unexpected version %v", versions[2])
+	}
+}
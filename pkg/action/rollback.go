@@ -0,0 +1,161 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/sitewhere/swctl/pkg/cli"
+	"github.com/sitewhere/swctl/pkg/install"
+	"github.com/sitewhere/swctl/pkg/status"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// defaultRollbackTimeout is the time to wait for the rollback
+const defaultRollbackTimeout = 300 * time.Second
+
+// Rollback is the action for rolling back SiteWhere Infrastructure release
+type Rollback struct {
+	cfg *action.Configuration
+
+	settings *cli.EnvSettings
+
+	// Revision to roll back to, 0 is the previous revision
+	Revision int
+	// Wait for the resources to be ready before running the verification
+	Wait bool
+	// Timeout to wait for the resources, and then for the components to be
+	// ready
+	Timeout time.Duration
+	// Use verbose mode
+	Verbose bool
+	// Progress is called when the status of a component changes
+	Progress ProgressFunc
+}
+
+// NewRollback constructs a new *Rollback
+func NewRollback(cfg *action.Configuration, settings *cli.EnvSettings) *Rollback {
+	return &Rollback{
+		cfg:      cfg,
+		settings: settings,
+		Revision: 0,
+		Wait:     false,
+		Timeout:  defaultRollbackTimeout,
+		Verbose:  false,
+		Progress: nil,
+	}
+}
+
+// Revisions returns the revisions of the release, the newest first
+func (i *Rollback) Revisions() ([]install.ReleaseRevision, error) {
+	if err := i.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	actionConfig, err := newReleaseConfiguration(i.settings, i.Verbose)
+	if err != nil {
+		return nil, err
+	}
+	history, err := action.NewHistory(actionConfig).Run(i.settings.ReleaseName)
+	if err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			return nil, errors.Errorf("release %s not found, install SiteWhere with `swctl install` first", i.settings.ReleaseName)
+		}
+		return nil, err
+	}
+	return releaseRevisions(history, 0), nil
+}
+
+// Run executes the rollback command, returning the status of each component
+// after the rollback. The components are polled until they are ready or the
+// timeout expires, the ones still not ready are reported without error.
+func (i *Rollback) Run() (*install.SiteWhereRollback, error) {
+	revisions, err := i.Revisions()
+	if err != nil {
+		return nil, err
+	}
+	target, err := rollbackTarget(revisions, i.Revision)
+	if err != nil {
+		return nil, err
+	}
+
+	actionConfig, err := newReleaseConfiguration(i.settings, i.Verbose)
+	if err != nil {
+		return nil, err
+	}
+	rollbackAction := action.NewRollback(actionConfig)
+	rollbackAction.Version = target
+	rollbackAction.Wait = i.Wait
+	rollbackAction.Timeout = i.Timeout
+	if err = rollbackAction.Run(i.settings.ReleaseName); err != nil {
+		return nil, err
+	}
+	rel, err := action.NewGet(actionConfig).Run(i.settings.ReleaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), i.Timeout)
+	defer cancel()
+	check := NewCheckInstall(i.cfg, i.settings)
+	components, err := waitForComponents(ctx, installPollInterval, func() ([]status.SiteWhereStatus, error) {
+		checked, err := check.Run()
+		if err != nil {
+			return nil, err
+		}
+		return checked.Components, nil
+	}, i.Progress)
+	if err != nil && ctx.Err() == nil {
+		return nil, err
+	}
+	return &install.SiteWhereRollback{
+		Release:          rel.Name,
+		Namespace:        rel.Namespace,
+		PreviousRevision: revisions[0].Revision,
+		TargetRevision:   target,
+		Revision:         rel.Version,
+		Components:       components,
+	}, nil
+}
+
+// rollbackTarget returns the revision to roll back to. Revision 0 is the one
+// before the current revision.
+func rollbackTarget(revisions []install.ReleaseRevision, revision int) (int, error) {
+	if len(revisions) == 0 {
+		return 0, errors.New("release has no revisions")
+	}
+	current := revisions[0].Revision
+	if revision == 0 {
+		if len(revisions) < 2 {
+			return 0, errors.Errorf("release has no revision before %d", current)
+		}
+		return revisions[1].Revision, nil
+	}
+	if revision == current {
+		return 0, errors.Errorf("revision %d is the current revision", revision)
+	}
+	for _, r := range revisions {
+		if r.Revision == revision {
+			return revision, nil
+		}
+	}
+	return 0, errors.Errorf("revision %d not found", revision)
+}
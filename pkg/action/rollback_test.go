@@ -0,0 +1,91 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"testing"
+
+	"github.com/sitewhere/swctl/pkg/install"
+)
+
+func TestRollbackTarget(t *testing.T) {
+	t.Parallel()
+	revisions := []install.ReleaseRevision{{Revision: 4}, {Revision: 3}, {Revision: 1}}
+	data := []struct {
+		name      string
+		revisions []install.ReleaseRevision
+		revision  int
+		expected  int
+		err       bool
+	}{
+		{
+			name:      "previous",
+			revisions: revisions,
+			revision:  0,
+			expected:  3,
+		},
+		{
+			name:      "chosen",
+			revisions: revisions,
+			revision:  1,
+			expected:  1,
+		},
+		{
+			name:      "current",
+			revisions: revisions,
+			revision:  4,
+			err:       true,
+		},
+		{
+			name:      "pruned",
+			revisions: revisions,
+			revision:  2,
+			err:       true,
+		},
+		{
+			name:      "single-revision",
+			revisions: []install.ReleaseRevision{{Revision: 1}},
+			revision:  0,
+			err:       true,
+		},
+	}
+	for _, single := range data {
+		t.Run(single.name, func(single struct {
+			name      string
+			revisions []install.ReleaseRevision
+			revision  int
+			expected  int
+			err       bool
+		}) func(t *testing.T) {
+			return func(t *testing.T) {
+				target, err := rollbackTarget(single.revisions, single.revision)
+				if single.err {
+					if err == nil {
+						t.Fatalf("expected error rolling back to %d", single.revision)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if target != single.expected {
+					t.Fatalf("expected revision %d, got %d", single.expected, target)
+				}
+			}
+		}(single))
+	}
+}
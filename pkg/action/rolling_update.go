@@ -0,0 +1,226 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	ctlcli "sigs.k8s.io/controller-runtime/pkg/client"
+
+	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
+
+	"github.com/sitewhere/swctl/pkg/instance"
+	"github.com/sitewhere/swctl/pkg/status"
+)
+
+// defaultRollingUpdateTimeout is the default time to wait for each
+// microservice to be available in a rolling update
+const defaultRollingUpdateTimeout = 300 * time.Second
+
+// rollingUpdate updates the microservices one at a time, in rolling order,
+// waiting for the Deployment of each one to be available. If a microservice
+// is not available in time, the updated microservices go back to their
+// previous DockerSpec and replicas.
+func (i *UpdateInstance) rollingUpdate(ctx context.Context, client ctlcli.Client, clientset kubernetes.Interface,
+	microservices []sitewhereiov1alpha4.SiteWhereMicroservice) ([]status.SiteWhereStatus, []instance.SpecChange, error) {
+	var steps []status.SiteWhereStatus
+	var changes []instance.SpecChange
+	var updated []*sitewhereiov1alpha4.SiteWhereMicroservice
+	ordered := rollingOrder(microservices, i.Order)
+	for idx, swMicroservice := range ordered {
+		previous := swMicroservice.DeepCopy()
+		generation, err := deploymentGeneration(ctx, clientset, swMicroservice)
+		if err != nil {
+			return steps, changes, err
+		}
+		msChanges, err := i.updateMicroservice(ctx, client, swMicroservice)
+		if err != nil {
+			return steps, changes, err
+		}
+		changes = append(changes, msChanges...)
+		if len(msChanges) == 0 || i.DryRun {
+			continue
+		}
+		updated = append(updated, previous)
+		step, err := waitForRollout(ctx, clientset, swMicroservice, generation, i.Timeout, i.pollInterval, i.Progress)
+		if err == nil {
+			step.Detail = "updated, " + step.Detail
+			steps = append(steps, step)
+			continue
+		}
+		step.Detail = err.Error()
+		steps = append(steps, step)
+		steps = append(steps, revertMicroservices(ctx, client, updated)...)
+		for _, skipped := range ordered[idx+1:] {
+			steps = append(steps, status.SiteWhereStatus{
+				Name:   skipped.GetName(),
+				Kind:   sitewhereiov1alpha4.SiteWhereMicroserviceKind,
+				Status: status.Skipped,
+				Detail: "not updated",
+			})
+		}
+		return steps, changes, errors.Wrapf(err, "microservice %s failed its health gate, the update was reverted", swMicroservice.GetName())
+	}
+	return steps, changes, nil
+}
+
+// rollingOrder sorts the microservices by the position of their functional
+// area in order, and then by name
+func rollingOrder(microservices []sitewhereiov1alpha4.SiteWhereMicroservice, order []string) []*sitewhereiov1alpha4.SiteWhereMicroservice {
+	var result []*sitewhereiov1alpha4.SiteWhereMicroservice
+	for idx := range microservices {
+		result = append(result, &microservices[idx])
+	}
+	position := func(ms *sitewhereiov1alpha4.SiteWhereMicroservice) int {
+		for idx, area := range order {
+			if area == ms.Spec.FunctionalArea || area == ms.GetName() {
+				return idx
+			}
+		}
+		return len(order)
+	}
+	sort.SliceStable(result, func(a, b int) bool {
+		if position(result[a]) != position(result[b]) {
+			return position(result[a]) < position(result[b])
+		}
+		return result[a].GetName() < result[b].GetName()
+	})
+	return result
+}
+
+// deploymentGeneration returns the generation of the Deployment of the
+// microservice before it is updated
+func deploymentGeneration(ctx context.Context, clientset kubernetes.Interface, swMicroservice *sitewhereiov1alpha4.SiteWhereMicroservice) (int64, error) {
+	if swMicroservice.Status.Deployment == "" {
+		return 0, fmt.Errorf("microservice %s has no deployment", swMicroservice.GetName())
+	}
+	deploy, err := clientset.AppsV1().Deployments(swMicroservice.GetNamespace()).Get(ctx, swMicroservice.Status.Deployment, metav1.GetOptions{})
+	if err != nil {
+		return 0, err
+	}
+	return deploy.GetGeneration(), nil
+}
+
+// waitForRollout waits until the Deployment of the microservice rolls out a
+// generation newer than generation and all of its replicas are available
+func waitForRollout(ctx context.Context, clientset kubernetes.Interface, swMicroservice *sitewhereiov1alpha4.SiteWhereMicroservice,
+	generation int64, timeout time.Duration, interval time.Duration, progress ProgressFunc) (status.SiteWhereStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var last = status.SiteWhereStatus{
+		Name:   swMicroservice.GetName(),
+		Kind:   sitewhereiov1alpha4.SiteWhereMicroserviceKind,
+		Status: status.NotReady,
+	}
+	_, err := waitForComponents(ctx, interval, func() ([]status.SiteWhereStatus, error) {
+		deploy, err := clientset.AppsV1().Deployments(swMicroservice.GetNamespace()).Get(ctx, swMicroservice.Status.Deployment, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		last = rolloutStatus(swMicroservice.GetName(), deploy, generation)
+		return []status.SiteWhereStatus{last}, nil
+	}, progress)
+	if err != nil && ctx.Err() != nil {
+		return last, fmt.Errorf("not available after %s: %s", timeout, last.Detail)
+	}
+	return last, err
+}
+
+// rolloutStatus returns the status of the roll out of a Deployment
+// generation newer than generation
+func rolloutStatus(name string, deploy *appsv1.Deployment, generation int64) status.SiteWhereStatus {
+	var desired int32 = 1
+	if deploy.Spec.Replicas != nil {
+		desired = *deploy.Spec.Replicas
+	}
+	result := status.SiteWhereStatus{
+		Name:   name,
+		Kind:   sitewhereiov1alpha4.SiteWhereMicroserviceKind,
+		Status: status.NotReady,
+		Detail: fmt.Sprintf("%d/%d updated, %d/%d available", deploy.Status.UpdatedReplicas, desired, deploy.Status.AvailableReplicas, desired),
+	}
+	if deploy.GetGeneration() <= generation || deploy.Status.ObservedGeneration < deploy.GetGeneration() {
+		result.Detail = "waiting for the deployment to be updated"
+		return result
+	}
+	if desired == 0 {
+		// Scaled to zero once every pod is gone
+		if deploy.Status.Replicas == 0 {
+			result.Status = status.Installed
+		} else {
+			result.Detail = fmt.Sprintf("%d replicas terminating", deploy.Status.Replicas)
+		}
+		return result
+	}
+	if deploy.Status.UpdatedReplicas >= desired && deploy.Status.AvailableReplicas >= desired && deploy.Status.UnavailableReplicas == 0 {
+		result.Status = status.Installed
+	}
+	return result
+}
+
+// revertMicroservices sets the DockerSpec and replicas of the microservices
+// back to the ones of their previous spec, last updated first
+func revertMicroservices(ctx context.Context, client ctlcli.Client, previous []*sitewhereiov1alpha4.SiteWhereMicroservice) []status.SiteWhereStatus {
+	var result []status.SiteWhereStatus
+	for idx := len(previous) - 1; idx >= 0; idx-- {
+		step := status.SiteWhereStatus{
+			Name:   previous[idx].GetName(),
+			Kind:   sitewhereiov1alpha4.SiteWhereMicroserviceKind,
+			Status: status.RolledBack,
+		}
+		if err := revertMicroservice(ctx, client, previous[idx]); err != nil {
+			step.Status = status.Unknown
+			step.Detail = fmt.Sprintf("revert failed: %s", err.Error())
+		} else {
+			step.Detail = fmt.Sprintf("reverted to %s, %d replicas", dockerTag(previous[idx]), previous[idx].Spec.Replicas)
+		}
+		result = append(result, step)
+	}
+	return result
+}
+
+func revertMicroservice(ctx context.Context, client ctlcli.Client, previous *sitewhereiov1alpha4.SiteWhereMicroservice) error {
+	var live sitewhereiov1alpha4.SiteWhereMicroservice
+	key := ctlcli.ObjectKey{Namespace: previous.GetNamespace(), Name: previous.GetName()}
+	if err := client.Get(ctx, key, &live); err != nil {
+		return err
+	}
+	reverted := live.DeepCopy()
+	reverted.Spec.Replicas = previous.Spec.Replicas
+	if previous.Spec.PodSpec != nil {
+		if reverted.Spec.PodSpec == nil {
+			reverted.Spec.PodSpec = &sitewhereiov1alpha4.MicroservicePodSpecification{}
+		}
+		reverted.Spec.PodSpec.DockerSpec = previous.Spec.PodSpec.DockerSpec
+	}
+	return client.Patch(ctx, reverted, ctlcli.MergeFrom(&live))
+}
+
+func dockerTag(swMicroservice *sitewhereiov1alpha4.SiteWhereMicroservice) string {
+	if swMicroservice.Spec.PodSpec == nil || swMicroservice.Spec.PodSpec.DockerSpec == nil {
+		return "the previous image"
+	}
+	return "tag " + swMicroservice.Spec.PodSpec.DockerSpec.Tag
+}
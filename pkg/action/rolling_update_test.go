@@ -0,0 +1,198 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	ctlcli "sigs.k8s.io/controller-runtime/pkg/client"
+	ctlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
+
+	"github.com/sitewhere/swctl/pkg/status"
+)
+
+func rollingMicroservice(name string) sitewhereiov1alpha4.SiteWhereMicroservice {
+	return sitewhereiov1alpha4.SiteWhereMicroservice{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "sitewhere"},
+		Spec: sitewhereiov1alpha4.SiteWhereMicroserviceSpec{
+			FunctionalArea: name,
+			Replicas:       1,
+			PodSpec: &sitewhereiov1alpha4.MicroservicePodSpecification{
+				DockerSpec: &sitewhereiov1alpha4.DockerSpec{Registry: "docker.io", Repository: "sitewhere", Tag: "3.0.5"},
+			},
+		},
+		Status: sitewhereiov1alpha4.SiteWhereMicroserviceStatus{Deployment: name},
+	}
+}
+
+func TestRollingOrder(t *testing.T) {
+	microservices := []sitewhereiov1alpha4.SiteWhereMicroservice{
+		rollingMicroservice("event-sources"),
+		rollingMicroservice("device-management"),
+		rollingMicroservice("instance-management"),
+		rollingMicroservice("asset-management"),
+	}
+	ordered := rollingOrder(microservices, []string{"instance-management", "event-sources"})
+	expected := []string{"instance-management", "event-sources", "asset-management", "device-management"}
+	for idx, name := range expected {
+		if ordered[idx].GetName() != name {
+			t.Fatalf("expected %s at %d, got %s", name, idx, ordered[idx].GetName())
+		}
+	}
+}
+
+func TestRolloutStatus(t *testing.T) {
+	t.Parallel()
+	replicas := int32(2)
+	zero := int32(0)
+	data := []struct {
+		name     string
+		deploy   appsv1.Deployment
+		expected status.Status
+	}{
+		{
+			name: "not-updated",
+			deploy: appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 1},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, UpdatedReplicas: 2, AvailableReplicas: 2},
+			},
+			expected: status.NotReady,
+		},
+		{
+			name: "rolling-out",
+			deploy: appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, UpdatedReplicas: 1, AvailableReplicas: 2, UnavailableReplicas: 1},
+			},
+			expected: status.NotReady,
+		},
+		{
+			name: "available",
+			deploy: appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+			},
+			expected: status.Installed,
+		},
+		{
+			name: "scaling-to-zero",
+			deploy: appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: &zero},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 1, AvailableReplicas: 1},
+			},
+			expected: status.NotReady,
+		},
+		{
+			name: "scaled-to-zero",
+			deploy: appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: &zero},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 2},
+			},
+			expected: status.Installed,
+		},
+	}
+	for _, single := range data {
+		t.Run(single.name, func(single struct {
+			name     string
+			deploy   appsv1.Deployment
+			expected status.Status
+		}) func(t *testing.T) {
+			return func(t *testing.T) {
+				result := rolloutStatus("device-management", &single.deploy, 1)
+				if result.Status != single.expected {
+					t.Fatalf("expected %s, got %s (%s)", single.expected, result.Status, result.Detail)
+				}
+			}
+		}(single))
+	}
+}
+
+func TestRollingUpdateRevert(t *testing.T) {
+	microservices := []sitewhereiov1alpha4.SiteWhereMicroservice{
+		rollingMicroservice("device-management"),
+		rollingMicroservice("instance-management"),
+		rollingMicroservice("event-sources"),
+	}
+	client := ctlfake.NewFakeClientWithScheme(scheme, microservices[0].DeepCopy(), microservices[1].DeepCopy(), microservices[2].DeepCopy())
+	clientset := fake.NewSimpleClientset()
+	var gets = map[string]int{}
+	clientset.PrependReactor("get", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		name := action.(k8stesting.GetAction).GetName()
+		gets[name]++
+		deploy := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "sitewhere", Generation: 1},
+			Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+		}
+		// instance-management rolls out, device-management never does
+		if name == "instance-management" && gets[name] > 1 {
+			deploy.Generation = 2
+			deploy.Status.ObservedGeneration = 2
+		}
+		return true, deploy, nil
+	})
+
+	u := NewUpdateInstance(nil)
+	replicas := int32(3)
+	u.Tag = "3.0.6"
+	u.Replicas = &replicas
+	u.Rolling = true
+	u.Timeout = 50 * time.Millisecond
+	u.pollInterval = 10 * time.Millisecond
+	ctx := context.TODO()
+	steps, _, err := u.rollingUpdate(ctx, client, clientset, microservices)
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	expected := []status.SiteWhereStatus{
+		{Name: "instance-management", Status: status.Installed},
+		{Name: "device-management", Status: status.NotReady},
+		{Name: "device-management", Status: status.RolledBack},
+		{Name: "instance-management", Status: status.RolledBack},
+		{Name: "event-sources", Status: status.Skipped},
+	}
+	if len(steps) != len(expected) {
+		t.Fatalf("expected %d steps, got %v", len(expected), steps)
+	}
+	for idx, step := range expected {
+		if steps[idx].Name != step.Name || steps[idx].Status != step.Status {
+			t.Fatalf("expected step %d %s %s, got %s %s", idx, step.Name, step.Status, steps[idx].Name, steps[idx].Status)
+		}
+	}
+	for _, name := range []string{"instance-management", "device-management", "event-sources"} {
+		var live sitewhereiov1alpha4.SiteWhereMicroservice
+		if err := client.Get(ctx, ctlcli.ObjectKey{Namespace: "sitewhere", Name: name}, &live); err != nil {
+			t.Fatalf(err.Error())
+		}
+		if live.Spec.PodSpec.DockerSpec.Tag != "3.0.5" || live.Spec.Replicas != 1 {
+			t.Fatalf("expected %s reverted to 1 replica of 3.0.5, got %d of %s", name, live.Spec.Replicas, live.Spec.PodSpec.DockerSpec.Tag)
+		}
+	}
+}
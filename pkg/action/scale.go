@@ -0,0 +1,182 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/client-go/kubernetes"
+	ctlcli "sigs.k8s.io/controller-runtime/pkg/client"
+
+	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
+
+	"github.com/sitewhere/swctl/pkg/instance"
+
+	"helm.sh/helm/v3/pkg/action"
+)
+
+// Scale is the action for setting the replicas of SiteWhere Microservices
+type Scale struct {
+	cfg *action.Configuration
+
+	// Name of the instance
+	InstanceName string
+	// Microservices to scale
+	Microservices []string
+	// All scales every microservice of the instance
+	All bool
+	// Replicas is the number of replicas
+	Replicas int32
+	// Wait for the replicas to be available
+	Wait bool
+	// Timeout for each microservice to be available
+	Timeout time.Duration
+	// Progress is called when the status of a scaled microservice changes
+	Progress ProgressFunc
+
+	pollInterval time.Duration
+}
+
+// NewScale constructs a new *Scale
+func NewScale(cfg *action.Configuration) *Scale {
+	return &Scale{
+		cfg:           cfg,
+		InstanceName:  "",
+		Microservices: nil,
+		All:           false,
+		Replicas:      0,
+		Wait:          false,
+		Timeout:       defaultRollingUpdateTimeout,
+		Progress:      nil,
+		pollInterval:  installPollInterval,
+	}
+}
+
+// Run executes the scale command, returning the replicas before and after
+func (i *Scale) Run() (*instance.ScaleSiteWhereInstance, error) {
+	if i.Replicas < 0 {
+		return nil, errors.New("the number of replicas must not be negative")
+	}
+	if i.All == (len(i.Microservices) > 0) {
+		return nil, errors.New("set the microservices to scale or use --all")
+	}
+	if err := i.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	client, err := ControllerClient(i.cfg)
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := i.cfg.KubernetesClientSet()
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.TODO()
+	_, namespace, err := getInstance(ctx, i.cfg, client, i.InstanceName)
+	if err != nil {
+		return nil, err
+	}
+	var swMicroserviceList sitewhereiov1alpha4.SiteWhereMicroserviceList
+	if err := client.List(ctx, &swMicroserviceList, ctlcli.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	result := &instance.ScaleSiteWhereInstance{
+		InstanceName: i.InstanceName,
+		Namespace:    namespace,
+	}
+	microservices, err := selectMicroservices(swMicroserviceList.Items, i.Microservices, i.All)
+	if err != nil {
+		return nil, err
+	}
+	return result, i.scale(ctx, client, clientset, microservices, result)
+}
+
+// selectMicroservices returns the microservices by name, or every
+// microservice if all is true
+func selectMicroservices(microservices []sitewhereiov1alpha4.SiteWhereMicroservice, names []string, all bool) ([]*sitewhereiov1alpha4.SiteWhereMicroservice, error) {
+	var result []*sitewhereiov1alpha4.SiteWhereMicroservice
+	var unknown []string
+	for _, name := range names {
+		found := false
+		for idx := range microservices {
+			if microservices[idx].GetName() == name {
+				result = append(result, &microservices[idx])
+				found = true
+				break
+			}
+		}
+		if !found {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown microservices: %s", strings.Join(unknown, ", "))
+	}
+	if all {
+		for idx := range microservices {
+			result = append(result, &microservices[idx])
+		}
+	}
+	return result, nil
+}
+
+// scale patches the replicas of the microservices, and then waits for the
+// Deployment of each scaled microservice if Wait is set
+func (i *Scale) scale(ctx context.Context, client ctlcli.Client, clientset kubernetes.Interface,
+	microservices []*sitewhereiov1alpha4.SiteWhereMicroservice, result *instance.ScaleSiteWhereInstance) error {
+	var generations = map[string]int64{}
+	for _, swMicroservice := range microservices {
+		scaled := instance.ScaledMicroservice{
+			Name:   swMicroservice.GetName(),
+			Before: swMicroservice.Spec.Replicas,
+			After:  i.Replicas,
+		}
+		if swMicroservice.Spec.Replicas != i.Replicas {
+			if i.Wait {
+				generation, err := deploymentGeneration(ctx, clientset, swMicroservice)
+				if err != nil {
+					return err
+				}
+				generations[swMicroservice.GetName()] = generation
+			}
+			updated := swMicroservice.DeepCopy()
+			updated.Spec.Replicas = i.Replicas
+			if err := client.Patch(ctx, updated, ctlcli.MergeFrom(swMicroservice)); err != nil {
+				return err
+			}
+		}
+		result.Microservices = append(result.Microservices, scaled)
+	}
+	if !i.Wait {
+		return nil
+	}
+	for _, swMicroservice := range microservices {
+		generation, ok := generations[swMicroservice.GetName()]
+		if !ok {
+			continue
+		}
+		if _, err := waitForRollout(ctx, clientset, swMicroservice, generation, i.Timeout, i.pollInterval, i.Progress); err != nil {
+			return errors.Wrapf(err, "microservice %s", swMicroservice.GetName())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/client-go/kubernetes/fake"
+	ctlcli "sigs.k8s.io/controller-runtime/pkg/client"
+	ctlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
+
+	"github.com/sitewhere/swctl/pkg/instance"
+)
+
+func TestSelectMicroservices(t *testing.T) {
+	microservices := []sitewhereiov1alpha4.SiteWhereMicroservice{
+		rollingMicroservice("device-management"),
+		rollingMicroservice("event-sources"),
+	}
+	selected, err := selectMicroservices(microservices, []string{"event-sources"}, false)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(selected) != 1 || selected[0].GetName() != "event-sources" {
+		t.Fatalf("expected event-sources, got %v", selected)
+	}
+	selected, err = selectMicroservices(microservices, nil, true)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(selected) != 2 {
+		t.Fatalf("expected 2 microservices, got %d", len(selected))
+	}
+	if _, err = selectMicroservices(microservices, []string{"asset-management"}, false); err == nil {
+		t.Fatalf("expected error for unknown microservice")
+	}
+}
+
+func TestScale(t *testing.T) {
+	deviceManagement := rollingMicroservice("device-management")
+	deviceManagement.Spec.Replicas = 1
+	eventSources := rollingMicroservice("event-sources")
+	eventSources.Spec.Replicas = 3
+	client := ctlfake.NewFakeClientWithScheme(scheme, deviceManagement.DeepCopy(), eventSources.DeepCopy())
+	ctx := context.TODO()
+
+	s := NewScale(nil)
+	s.Replicas = 3
+	result := &instance.ScaleSiteWhereInstance{}
+	err := s.scale(ctx, client, fake.NewSimpleClientset(), []*sitewhereiov1alpha4.SiteWhereMicroservice{&deviceManagement, &eventSources}, result)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	expected := []instance.ScaledMicroservice{
+		{Name: "device-management", Before: 1, After: 3},
+		{Name: "event-sources", Before: 3, After: 3},
+	}
+	for idx, scaled := range expected {
+		if result.Microservices[idx] != scaled {
+			t.Fatalf("expected %v, got %v", scaled, result.Microservices[idx])
+		}
+	}
+	var live sitewhereiov1alpha4.SiteWhereMicroservice
+	if err := client.Get(ctx, ctlcli.ObjectKey{Namespace: "sitewhere", Name: "device-management"}, &live); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if live.Spec.Replicas != 3 {
+		t.Fatalf("expected 3 replicas, got %d", live.Spec.Replicas)
+	}
+}
+
+func TestScaleToZero(t *testing.T) {
+	eventSources := rollingMicroservice("event-sources")
+	eventSources.Spec.Replicas = 2
+	client := ctlfake.NewFakeClientWithScheme(scheme, eventSources.DeepCopy())
+	ctx := context.TODO()
+
+	s := NewScale(nil)
+	s.Replicas = 0
+	result := &instance.ScaleSiteWhereInstance{}
+	err := s.scale(ctx, client, fake.NewSimpleClientset(), []*sitewhereiov1alpha4.SiteWhereMicroservice{&eventSources}, result)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	var live sitewhereiov1alpha4.SiteWhereMicroservice
+	if err := client.Get(ctx, ctlcli.ObjectKey{Namespace: "sitewhere", Name: "event-sources"}, &live); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if live.Spec.Replicas != 0 {
+		t.Fatalf("expected 0 replicas, got %d", live.Spec.Replicas)
+	}
+}
@@ -0,0 +1,130 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"github.com/sitewhere/swctl/pkg/config"
+)
+
+// sizingValues builds the values of SiteWhere Infrastructure Helm Chart
+// for a sizing preset.
+func sizingValues(preset *config.SizingPreset) map[string]interface{} {
+	vals := map[string]interface{}{}
+
+	// Kafka brokers and Zookeeper
+	kafka := componentSizeValues(preset.Kafka)
+	if preset.Kafka.Replicas > 0 {
+		kafka["replicas"] = preset.Kafka.Replicas
+		kafka["isr"] = minInt(preset.Kafka.Replicas, 2)
+	}
+	if preset.Kafka.Storage != "" {
+		kafka["storage"] = map[string]interface{}{"size": preset.Kafka.Storage}
+	}
+	zookeeper := componentSizeValues(preset.Zookeeper)
+	if preset.Zookeeper.Replicas > 0 {
+		zookeeper["replicas"] = preset.Zookeeper.Replicas
+	}
+	if preset.Zookeeper.Storage != "" {
+		zookeeper["storage"] = map[string]interface{}{"size": preset.Zookeeper.Storage}
+	}
+	if len(zookeeper) > 0 {
+		kafka["zookeeper"] = zookeeper
+	}
+	vals = mergeValues(vals, map[string]interface{}{"strimzi": kafka})
+
+	// PostgreSQL, the replicas after the first one are read replicas
+	postgresql := componentSizeValues(preset.PostgreSQL)
+	if preset.PostgreSQL.Replicas > 1 {
+		postgresql["replication"] = map[string]interface{}{
+			"enabled":       true,
+			"slaveReplicas": preset.PostgreSQL.Replicas - 1,
+		}
+	}
+	if preset.PostgreSQL.Storage != "" {
+		postgresql["persistence"] = map[string]interface{}{"size": preset.PostgreSQL.Storage}
+	}
+	vals = mergeValues(vals, map[string]interface{}{"postgresql": postgresql})
+
+	// InfluxDB runs a single replica
+	influxdb := componentSizeValues(preset.InfluxDB)
+	if preset.InfluxDB.Storage != "" {
+		influxdb["persistence"] = map[string]interface{}{"size": preset.InfluxDB.Storage}
+	}
+	vals = mergeValues(vals, map[string]interface{}{"influxdb": influxdb})
+
+	// Redis master and slaves
+	redisResources := componentSizeValues(preset.Redis)
+	if preset.Redis.Storage != "" {
+		redisResources["persistence"] = map[string]interface{}{"size": preset.Redis.Storage}
+	}
+	redis := map[string]interface{}{}
+	if len(redisResources) > 0 {
+		redis["master"] = redisResources
+		redis["slave"] = redisResources
+	}
+	if preset.Redis.Replicas > 0 {
+		redis["cluster"] = map[string]interface{}{"slaveCount": preset.Redis.Replicas}
+	}
+	vals = mergeValues(vals, map[string]interface{}{"redis": redis})
+
+	// Keycloak and its database
+	keycloak := componentSizeValues(preset.Keycloak)
+	if preset.Keycloak.Replicas > 0 {
+		keycloak["replicas"] = preset.Keycloak.Replicas
+	}
+	if preset.Keycloak.Storage != "" {
+		keycloak["postgresql"] = map[string]interface{}{
+			"persistence": map[string]interface{}{"size": preset.Keycloak.Storage},
+		}
+	}
+	vals = mergeValues(vals, map[string]interface{}{"keycloak": keycloak})
+
+	return vals
+}
+
+// componentSizeValues returns the resources values of a component
+func componentSizeValues(size config.ComponentSize) map[string]interface{} {
+	resources := map[string]interface{}{}
+	if requests := resourcesValues(size.Requests); len(requests) > 0 {
+		resources["requests"] = requests
+	}
+	if limits := resourcesValues(size.Limits); len(limits) > 0 {
+		resources["limits"] = limits
+	}
+	if len(resources) == 0 {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{"resources": resources}
+}
+
+func resourcesValues(resources config.Resources) map[string]interface{} {
+	vals := map[string]interface{}{}
+	if resources.CPU != "" {
+		vals["cpu"] = resources.CPU
+	}
+	if resources.Memory != "" {
+		vals["memory"] = resources.Memory
+	}
+	return vals
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
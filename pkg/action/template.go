@@ -0,0 +1,141 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/sitewhere/swctl/pkg/install"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/getter"
+)
+
+const (
+	templateValuesFile   = "values.yaml"
+	templateCRDsFile     = "crds.yaml"
+	templateManifestFile = "manifest.yaml"
+)
+
+// Template renders the manifests that install would apply, without
+// contacting the Kubernetes API server. If the chart version is present
+// in the local repository cache, the chart repository is not updated.
+func (i *Install) Template() (*install.SiteWhereTemplate, error) {
+	if _, cached := cachedChartPath(i.HelmChartVersion, i.settings); !cached {
+		if err := i.prepareRepository(i.settings); err != nil {
+			return nil, err
+		}
+	}
+	return i.renderRelease()
+}
+
+func (i *Install) renderRelease() (*install.SiteWhereTemplate, error) {
+	var logConf action.DebugLog = Discardf
+	if i.Verbose {
+		logConf = log.Printf
+	}
+	actionConfig := &action.Configuration{Log: logConf}
+
+	installAction := action.NewInstall(actionConfig)
+	installAction.DryRun = true
+	installAction.ClientOnly = true
+	installAction.Replace = true
+	installAction.Namespace = i.settings.SystemNamespace
+	installAction.ReleaseName = i.settings.ReleaseName
+	installAction.SkipCRDs = i.SkipCRD
+	installAction.Version = i.HelmChartVersion
+
+	vals, err := i.MergeValues(getter.All(i.settings.EnvSettings))
+	if err != nil {
+		return nil, err
+	}
+
+	chartRequested, err := loadSiteWhereChart(&installAction.ChartPathOptions, i.ChartPath, installAction.DependencyUpdate, i.settings)
+	if err != nil {
+		return nil, err
+	}
+
+	mergedValues, err := chartutil.CoalesceValues(chartRequested, vals)
+	if err != nil {
+		return nil, err
+	}
+
+	rel, err := installAction.Run(chartRequested, vals)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest strings.Builder
+	manifest.WriteString(rel.Manifest)
+	for _, hook := range rel.Hooks {
+		fmt.Fprintf(&manifest, "---\n# Source: %s\n%s\n", hook.Path, hook.Manifest)
+	}
+
+	var crds strings.Builder
+	if !i.SkipCRD {
+		for _, crd := range chartRequested.CRDObjects() {
+			fmt.Fprintf(&crds, "---\n# Source: %s\n%s\n", crd.Filename, string(crd.File.Data))
+		}
+	}
+
+	result := &install.SiteWhereTemplate{
+		Release:      rel.Name,
+		Namespace:    rel.Namespace,
+		ChartVersion: chartRequested.Metadata.Version,
+		Values:       mergedValues,
+		CRDs:         crds.String(),
+		Manifest:     manifest.String(),
+	}
+
+	if i.OutputDir != "" {
+		if err = writeTemplate(i.OutputDir, result); err != nil {
+			return nil, err
+		}
+		result.OutputDir = i.OutputDir
+	}
+	return result, nil
+}
+
+func writeTemplate(outputDir string, template *install.SiteWhereTemplate) error {
+	err := os.MkdirAll(outputDir, 0755)
+	if err != nil {
+		return err
+	}
+	values, err := yaml.Marshal(template.Values)
+	if err != nil {
+		return err
+	}
+	files := map[string][]byte{
+		templateValuesFile:   values,
+		templateCRDsFile:     []byte(template.CRDs),
+		templateManifestFile: []byte(template.Manifest),
+	}
+	for name, content := range files {
+		if err = ioutil.WriteFile(filepath.Join(outputDir, name), content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
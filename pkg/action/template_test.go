@@ -0,0 +1,182 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sitewhere/swctl/pkg/cli"
+
+	helmcli "helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/cli/values"
+)
+
+// writeTestChart writes a minimal SiteWhere Infrastructure chart that renders
+// the values used by the tests
+func writeTestChart(t *testing.T, dir string) {
+	files := map[string]string{
+		"Chart.yaml": `apiVersion: v2
+name: sitewhere-infrastructure
+version: 0.1.0
+`,
+		"values.yaml": `operator:
+  enabled: true
+keycloak:
+  enabled: true
+  replicas: 1
+strimzi:
+  enabled: true
+`,
+		"templates/values.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}-values
+  namespace: {{ .Release.Namespace }}
+data:
+  operator: {{ quote .Values.operator.enabled }}
+  keycloak: {{ quote .Values.keycloak.replicas }}
+  strimzi: {{ quote .Values.strimzi.enabled }}
+`,
+		"crds/instances.yaml": `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: instances.sitewhere.io
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestInstallTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "swctl-chart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeTestChart(t, dir)
+
+	data := []struct {
+		name     string
+		skipCRD  bool
+		options  values.Options
+		expected []string
+		crds     bool
+	}{
+		{
+			name:    "defaults",
+			skipCRD: false,
+			expected: []string{
+				"name: sitewhere-values",
+				"namespace: sitewhere-system",
+				`operator: "true"`,
+				`keycloak: "1"`,
+				`strimzi: "true"`,
+			},
+			crds: true,
+		},
+		{
+			name:    "set-overrides",
+			skipCRD: true,
+			options: values.Options{
+				Values:       []string{"keycloak.replicas=3", "operator.enabled=false"},
+				StringValues: []string{"strimzi.enabled=no"},
+			},
+			expected: []string{
+				`operator: "false"`,
+				`keycloak: "3"`,
+				`strimzi: "no"`,
+			},
+			crds: false,
+		},
+	}
+	for _, single := range data {
+		t.Run(single.name, func(single struct {
+			name     string
+			skipCRD  bool
+			options  values.Options
+			expected []string
+			crds     bool
+		}) func(t *testing.T) {
+			return func(t *testing.T) {
+				settings := &cli.EnvSettings{
+					EnvSettings:     helmcli.New(),
+					SystemNamespace: cli.DefaultNamespace,
+					ReleaseName:     cli.DefaultReleaseName,
+					ChartName:       cli.DefaultChartName,
+				}
+				client := NewInstall(nil, settings)
+				client.ChartPath = dir
+				client.SkipCRD = single.skipCRD
+				client.ValueOptions = single.options
+				result, err := client.Template()
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if result.ChartVersion != "0.1.0" || result.Namespace != cli.DefaultNamespace {
+					t.Errorf("expected chart 0.1.0 in %s, got %s in %s", cli.DefaultNamespace, result.ChartVersion, result.Namespace)
+				}
+				for _, expected := range single.expected {
+					if !strings.Contains(result.Manifest, expected) {
+						t.Errorf("expected manifest to contain %s, got:\n%s", expected, result.Manifest)
+					}
+				}
+				if hasCRDs := strings.Contains(result.CRDs, "instances.sitewhere.io"); hasCRDs != single.crds {
+					t.Errorf("expected CRDs rendered %t, got %t", single.crds, hasCRDs)
+				}
+			}
+		}(single))
+	}
+}
+
+func TestWriteTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "swctl-template")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	chartDir := filepath.Join(dir, "chart")
+	writeTestChart(t, chartDir)
+
+	settings := &cli.EnvSettings{
+		EnvSettings:     helmcli.New(),
+		SystemNamespace: cli.DefaultNamespace,
+		ReleaseName:     cli.DefaultReleaseName,
+		ChartName:       cli.DefaultChartName,
+	}
+	client := NewInstall(nil, settings)
+	client.ChartPath = chartDir
+	client.OutputDir = filepath.Join(dir, "out")
+	if _, err = client.Template(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{templateValuesFile, templateCRDsFile, templateManifestFile} {
+		if _, err := os.Stat(filepath.Join(client.OutputDir, name)); err != nil {
+			t.Errorf("expected %s to be written: %v", name, err)
+		}
+	}
+}
@@ -17,16 +17,29 @@
 package action
 
 import (
-	"github.com/sitewhere/swctl/pkg/resources"
-	"log"
-	"os"
+	"context"
+	"fmt"
+	"time"
 
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sitewhere/swctl/pkg/cli"
 	"github.com/sitewhere/swctl/pkg/install"
 
+	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
+
 	"helm.sh/helm/v3/pkg/action"
-	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/storage/driver"
 )
 
+// defaultUninstallTimeout is the time to wait for the namespaces to terminate
+const defaultUninstallTimeout = 300 * time.Second
+
 // Uninstall is the action for installing SiteWhere
 type Uninstall struct {
 	cfg *action.Configuration
@@ -37,6 +50,8 @@ type Uninstall struct {
 	Verbose bool
 	// Purge data
 	Purge bool
+	// Timeout to wait for the namespaces to terminate when purging
+	Timeout time.Duration
 }
 
 // NewUninstall constructs a new *Uninstall
@@ -46,51 +61,165 @@ func NewUninstall(cfg *action.Configuration, settings *cli.EnvSettings) *Uninsta
 		settings: settings,
 		Verbose:  false,
 		Purge:    false,
+		Timeout:  defaultUninstallTimeout,
 	}
 }
 
-// Run executes the uninstall command, returning the result of the uninstallation
+// Run executes the uninstall command, returning the result of the uninstallation.
+// If Purge is set, every resource owned by SiteWhere is deleted.
 func (i *Uninstall) Run() (*install.SiteWhereInstall, error) {
 	var err error
 	if err = i.cfg.KubeClient.IsReachable(); err != nil {
 		return nil, err
 	}
-	result, err := i.uninstallRelease()
-	if err != nil {
-		return nil, err
-	}
 	if i.Purge {
-		clientSet, err := i.cfg.KubernetesClientSet()
+		inventory, err := i.Inventory()
 		if err != nil {
 			return nil, err
 		}
-		err = resources.DeleteSiteWhereNamespaceIfExists(clientSet)
+		return i.RunPurge(inventory)
+	}
+	return i.uninstallRelease()
+}
+
+// Inventory finds the resources owned by SiteWhere, in deletion order
+func (i *Uninstall) Inventory() (*install.SiteWhereInventory, error) {
+	if err := i.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	clientset, err := i.cfg.KubernetesClientSet()
+	if err != nil {
+		return nil, err
+	}
+	extensionsClient, err := KubernetesAPIExtensionClientSet(i.cfg)
+	if err != nil {
+		return nil, err
+	}
+	instanceNames, namespaces, err := i.listInstances(clientset)
+	if err != nil {
+		return nil, err
+	}
+	releaseExists, err := i.releaseExists()
+	if err != nil {
+		return nil, err
+	}
+	return buildInventory(context.TODO(), clientset, extensionsClient, instanceNames, namespaces,
+		i.settings.ReleaseName, i.settings.SystemNamespace, releaseExists)
+}
+
+// RunPurge deletes the resources of the inventory in order, and waits for
+// the namespaces to terminate
+func (i *Uninstall) RunPurge(inventory *install.SiteWhereInventory) (*install.SiteWhereInstall, error) {
+	clientset, err := i.cfg.KubernetesClientSet()
+	if err != nil {
+		return nil, err
+	}
+	extensionsClient, err := KubernetesAPIExtensionClientSet(i.cfg)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.TODO()
+	result := &install.SiteWhereInstall{
+		Release:   inventory.Release,
+		Namespace: inventory.Namespace,
+	}
+	var namespaces []string
+	for _, item := range inventory.Items {
+		switch item.Kind {
+		case sitewhereiov1alpha4.SiteWhereInstanceKind:
+			err = i.deleteInstance(ctx, item.Name)
+		case helmReleaseKind:
+			_, err = i.uninstallRelease()
+		default:
+			err = deleteInventoryItem(ctx, clientset, extensionsClient, item)
+		}
+		if err == nil && item.Kind == namespaceKind {
+			namespaces = append(namespaces, item.Name)
+		}
 		if err != nil {
-			return nil, err
+			result.Errors = append(result.Errors, fmt.Sprintf("%s %s: %s", item.Kind, inventoryItemName(item), err))
 		}
+		result.Components = append(result.Components, deletedStatus(item, err))
+	}
+	if len(result.Errors) > 0 {
+		return result, errors.Errorf("%d SiteWhere resources could not be deleted", len(result.Errors))
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, i.Timeout)
+	defer cancel()
+	if err = waitForNamespacesDeleted(waitCtx, clientset, namespaces, namespacePollInterval); err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		return result, err
 	}
 	return result, nil
 }
 
-func (i *Uninstall) uninstallRelease() (*install.SiteWhereInstall, error) {
-	actionConfig := new(action.Configuration)
-	// You can pass an empty string instead of settings.Namespace() to list
-	// all namespaces
+// listInstances returns the names of the instances and the namespaces
+// controlled by them. A namespace that is not controlled by its instance
+// was not created by the operator and is not deleted.
+func (i *Uninstall) listInstances(clientset kubernetes.Interface) ([]string, []string, error) {
+	client, err := ControllerClient(i.cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx := context.TODO()
+	var instances sitewhereiov1alpha4.SiteWhereInstanceList
+	if err := client.List(ctx, &instances); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	clusterNamespaces, err := listNamespaces(ctx, clientset)
+	if err != nil {
+		return nil, nil, err
+	}
+	var names []string
+	var namespaces []string
+	for idx := range instances.Items {
+		names = append(names, instances.Items[idx].GetName())
+		if namespace, ok := findControlledNamespace(&instances.Items[idx], clusterNamespaces); ok {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return names, namespaces, nil
+}
+
+func (i *Uninstall) deleteInstance(ctx context.Context, name string) error {
+	client, err := ControllerClient(i.cfg)
+	if err != nil {
+		return err
+	}
+	var swInstance sitewhereiov1alpha4.SiteWhereInstance
+	if err := client.Get(ctx, types.NamespacedName{Name: name}, &swInstance); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return client.Delete(ctx, &swInstance)
+}
 
-	var logConf action.DebugLog
-	if i.Verbose {
-		logConf = log.Printf
-	} else {
-		logConf = Discardf
+func (i *Uninstall) releaseExists() (bool, error) {
+	actionConfig, err := newReleaseConfiguration(i.settings, i.Verbose)
+	if err != nil {
+		return false, err
+	}
+	_, err = action.NewStatus(actionConfig).Run(i.settings.ReleaseName)
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		return false, nil
 	}
+	return err == nil, err
+}
 
-	if err := actionConfig.Init(i.settings.RESTClientGetter(), sitewhereSystemNamespace, os.Getenv("HELM_DRIVER"), logConf); err != nil {
+func (i *Uninstall) uninstallRelease() (*install.SiteWhereInstall, error) {
+	actionConfig, err := newReleaseConfiguration(i.settings, i.Verbose)
+	if err != nil {
 		return nil, err
 	}
 
 	uninstallAction := action.NewUninstall(actionConfig)
 
-	res, err := uninstallAction.Run(sitewhereReleaseName)
+	res, err := uninstallAction.Run(i.settings.ReleaseName)
 
 	if err != nil {
 		return nil, err
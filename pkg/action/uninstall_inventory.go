@@ -0,0 +1,215 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sitewhere/swctl/pkg/install"
+	"github.com/sitewhere/swctl/pkg/status"
+
+	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
+)
+
+const (
+	pvcKind                = "PersistentVolumeClaim"
+	clusterRoleKind        = "ClusterRole"
+	clusterRoleBindingKind = "ClusterRoleBinding"
+)
+
+// sitewhereLabelSelector selects the resources labeled by SiteWhere Operator
+const sitewhereLabelSelector = "app=sitewhere"
+
+// namespacePollInterval is the time between two checks while waiting for
+// the namespaces to terminate
+const namespacePollInterval = 2 * time.Second
+
+// buildInventory finds the resources owned by SiteWhere: the instances and
+// their namespaces, the labeled namespaces, the PVCs of these namespaces, the
+// labeled cluster RBAC, the CRDs of sitewhere.io groups and the release and
+// its namespace. The items are in deletion order.
+func buildInventory(ctx context.Context, clientset kubernetes.Interface, extensionsClient clientset.Interface,
+	instanceNames []string, instanceNamespaces []string, release string, systemNamespace string, releaseExists bool) (*install.SiteWhereInventory, error) {
+	inventory := &install.SiteWhereInventory{Release: release, Namespace: systemNamespace}
+
+	for _, name := range instanceNames {
+		inventory.Items = append(inventory.Items, install.InventoryItem{Kind: sitewhereiov1alpha4.SiteWhereInstanceKind, Name: name})
+	}
+	if releaseExists {
+		inventory.Items = append(inventory.Items, install.InventoryItem{Kind: helmReleaseKind, Name: release, Namespace: systemNamespace})
+	}
+
+	// instance namespaces, labeled namespaces and then the system namespace
+	var namespaces []string
+	var seen = map[string]bool{}
+	addNamespace := func(name string) error {
+		if seen[name] {
+			return nil
+		}
+		seen[name] = true
+		_, err := clientset.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		namespaces = append(namespaces, name)
+		return nil
+	}
+	for _, name := range instanceNamespaces {
+		if err := addNamespace(name); err != nil {
+			return nil, err
+		}
+	}
+	labeled, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: sitewhereLabelSelector})
+	if err != nil {
+		return nil, err
+	}
+	for _, ns := range labeled.Items {
+		if ns.GetName() != systemNamespace {
+			if err := addNamespace(ns.GetName()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := addNamespace(systemNamespace); err != nil {
+		return nil, err
+	}
+
+	for _, ns := range namespaces {
+		pvcs, err := clientset.CoreV1().PersistentVolumeClaims(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, pvc := range pvcs.Items {
+			inventory.Items = append(inventory.Items, install.InventoryItem{Kind: pvcKind, Name: pvc.GetName(), Namespace: ns})
+		}
+	}
+
+	bindings, err := clientset.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{LabelSelector: sitewhereLabelSelector})
+	if err != nil {
+		return nil, err
+	}
+	for _, binding := range bindings.Items {
+		inventory.Items = append(inventory.Items, install.InventoryItem{Kind: clusterRoleBindingKind, Name: binding.GetName()})
+	}
+	roles, err := clientset.RbacV1().ClusterRoles().List(ctx, metav1.ListOptions{LabelSelector: sitewhereLabelSelector})
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles.Items {
+		inventory.Items = append(inventory.Items, install.InventoryItem{Kind: clusterRoleKind, Name: role.GetName()})
+	}
+
+	crds, err := extensionsClient.ApiextensionsV1().CustomResourceDefinitions().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, crd := range crds.Items {
+		if isSiteWhereCRDGroup(crd.Spec.Group) {
+			inventory.Items = append(inventory.Items, install.InventoryItem{Kind: crdKind, Name: crd.GetName()})
+		}
+	}
+
+	for _, ns := range namespaces {
+		inventory.Items = append(inventory.Items, install.InventoryItem{Kind: namespaceKind, Name: ns})
+	}
+	return inventory, nil
+}
+
+// deleteInventoryItem deletes a cluster-scoped or namespaced inventory item.
+// Instances and releases are deleted by the caller.
+func deleteInventoryItem(ctx context.Context, clientset kubernetes.Interface, extensionsClient clientset.Interface, item install.InventoryItem) error {
+	var err error
+	switch item.Kind {
+	case pvcKind:
+		err = clientset.CoreV1().PersistentVolumeClaims(item.Namespace).Delete(ctx, item.Name, metav1.DeleteOptions{})
+	case clusterRoleBindingKind:
+		err = clientset.RbacV1().ClusterRoleBindings().Delete(ctx, item.Name, metav1.DeleteOptions{})
+	case clusterRoleKind:
+		err = clientset.RbacV1().ClusterRoles().Delete(ctx, item.Name, metav1.DeleteOptions{})
+	case crdKind:
+		err = extensionsClient.ApiextensionsV1().CustomResourceDefinitions().Delete(ctx, item.Name, metav1.DeleteOptions{})
+	case namespaceKind:
+		return deleteNamespace(ctx, clientset, item.Name)
+	default:
+		return errors.Errorf("cannot delete %s %s", item.Kind, item.Name)
+	}
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// deleteNamespace deletes a namespace. A namespace already gone or
+// terminating, for instance garbage collected with its instance, is not an
+// error.
+func deleteNamespace(ctx context.Context, clientset kubernetes.Interface, name string) error {
+	err := clientset.CoreV1().Namespaces().Delete(ctx, name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+		return nil
+	}
+	return err
+}
+
+// waitForNamespacesDeleted waits until the namespaces are gone or the context is done
+func waitForNamespacesDeleted(ctx context.Context, clientset kubernetes.Interface, namespaces []string, interval time.Duration) error {
+	for {
+		var remaining []string
+		for _, ns := range namespaces {
+			_, err := clientset.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			remaining = append(remaining, ns)
+		}
+		if len(remaining) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Errorf("timed out waiting for namespaces to terminate: %s", strings.Join(remaining, ", "))
+		case <-time.After(interval):
+		}
+	}
+}
+
+// inventoryItemName returns the name of an item, prefixed by its namespace
+func inventoryItemName(item install.InventoryItem) string {
+	if item.Namespace != "" && item.Kind != helmReleaseKind {
+		return fmt.Sprintf("%s/%s", item.Namespace, item.Name)
+	}
+	return item.Name
+}
+
+func deletedStatus(item install.InventoryItem, err error) status.SiteWhereStatus {
+	return rolledBackStatus(inventoryItemName(item), item.Kind, err)
+}
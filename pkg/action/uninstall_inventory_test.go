@@ -0,0 +1,183 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	extensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/sitewhere/swctl/pkg/install"
+)
+
+func TestBuildInventory(t *testing.T) {
+	labels := map[string]string{"app": "sitewhere"}
+	clientset := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "sitewhere-system", Labels: labels}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "sitewhere"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data-kafka-0", Namespace: "sitewhere-system"}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "other"}},
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "sitewhere:instance", Labels: labels}},
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "admin"}},
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "sitewhere:instance:sitewhere", Labels: labels}},
+	)
+	extensionsClient := extensionsfake.NewSimpleClientset(
+		&apiextv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "instances.sitewhere.io"},
+			Spec:       apiextv1.CustomResourceDefinitionSpec{Group: "sitewhere.io"},
+		},
+		&apiextv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "kafkas.kafka.strimzi.io"},
+			Spec:       apiextv1.CustomResourceDefinitionSpec{Group: "kafka.strimzi.io"},
+		},
+	)
+	inventory, err := buildInventory(context.TODO(), clientset, extensionsClient,
+		[]string{"sitewhere", "gone"}, []string{"sitewhere", "gone"}, "sitewhere", "sitewhere-system", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []install.InventoryItem{
+		{Kind: "SiteWhereInstance", Name: "sitewhere"},
+		{Kind: "SiteWhereInstance", Name: "gone"},
+		{Kind: helmReleaseKind, Name: "sitewhere", Namespace: "sitewhere-system"},
+		{Kind: pvcKind, Name: "data-kafka-0", Namespace: "sitewhere-system"},
+		{Kind: clusterRoleBindingKind, Name: "sitewhere:instance:sitewhere"},
+		{Kind: clusterRoleKind, Name: "sitewhere:instance"},
+		{Kind: crdKind, Name: "instances.sitewhere.io"},
+		{Kind: namespaceKind, Name: "sitewhere"},
+		{Kind: namespaceKind, Name: "sitewhere-system"},
+	}
+	if len(inventory.Items) != len(expected) {
+		t.Fatalf("expected %d items, got %v", len(expected), inventory.Items)
+	}
+	for i, item := range expected {
+		if inventory.Items[i] != item {
+			t.Fatalf("expected item %d to be %v, got %v", i, item, inventory.Items[i])
+		}
+	}
+}
+
+func TestDeleteInventoryItem(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		name string
+		item install.InventoryItem
+		err  bool
+	}{
+		{
+			name: "pvc",
+			item: install.InventoryItem{Kind: pvcKind, Name: "data", Namespace: "sitewhere"},
+		},
+		{
+			name: "cluster-role",
+			item: install.InventoryItem{Kind: clusterRoleKind, Name: "sitewhere:instance"},
+		},
+		{
+			name: "namespace",
+			item: install.InventoryItem{Kind: namespaceKind, Name: "sitewhere"},
+		},
+		{
+			name: "not-found",
+			item: install.InventoryItem{Kind: crdKind, Name: "tenants.sitewhere.io"},
+		},
+		{
+			name: "unknown-kind",
+			item: install.InventoryItem{Kind: "Secret", Name: "sitewhere"},
+			err:  true,
+		},
+	}
+	for _, single := range data {
+		t.Run(single.name, func(single struct {
+			name string
+			item install.InventoryItem
+			err  bool
+		}) func(t *testing.T) {
+			return func(t *testing.T) {
+				clientset := fake.NewSimpleClientset(
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "sitewhere"}},
+					&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "sitewhere"}},
+					&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "sitewhere:instance"}},
+				)
+				extensionsClient := extensionsfake.NewSimpleClientset()
+				err := deleteInventoryItem(context.TODO(), clientset, extensionsClient, single.item)
+				if single.err {
+					if err == nil {
+						t.Fatalf("expected error deleting %s", single.item.Kind)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				var getErr error
+				switch single.item.Kind {
+				case pvcKind:
+					_, getErr = clientset.CoreV1().PersistentVolumeClaims(single.item.Namespace).Get(context.TODO(), single.item.Name, metav1.GetOptions{})
+				case clusterRoleKind:
+					_, getErr = clientset.RbacV1().ClusterRoles().Get(context.TODO(), single.item.Name, metav1.GetOptions{})
+				case namespaceKind:
+					_, getErr = clientset.CoreV1().Namespaces().Get(context.TODO(), single.item.Name, metav1.GetOptions{})
+				default:
+					return
+				}
+				if !apierrors.IsNotFound(getErr) {
+					t.Fatalf("expected %s %s to be deleted", single.item.Kind, single.item.Name)
+				}
+			}
+		}(single))
+	}
+}
+
+func TestDeleteInventoryItemTerminatingNamespace(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "sitewhere"}})
+	clientset.PrependReactor("delete", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewConflict(corev1.Resource("namespaces"), "sitewhere",
+			errors.New("the namespace is being terminated"))
+	})
+	item := install.InventoryItem{Kind: namespaceKind, Name: "sitewhere"}
+	if err := deleteInventoryItem(context.TODO(), clientset, extensionsfake.NewSimpleClientset(), item); err != nil {
+		t.Fatalf("expected a terminating namespace to be deleted, got %v", err)
+	}
+}
+
+func TestWaitForNamespacesDeleted(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "sitewhere"}})
+	ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel()
+	if err := waitForNamespacesDeleted(ctx, clientset, []string{"sitewhere"}, 10*time.Millisecond); err == nil {
+		t.Fatalf("expected timeout waiting for namespace")
+	}
+	if err := clientset.CoreV1().Namespaces().Delete(context.TODO(), "sitewhere", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := waitForNamespacesDeleted(context.TODO(), clientset, []string{"sitewhere"}, 10*time.Millisecond); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
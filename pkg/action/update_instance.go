@@ -0,0 +1,320 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctlcli "sigs.k8s.io/controller-runtime/pkg/client"
+
+	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
+
+	"github.com/sitewhere/swctl/pkg/instance"
+
+	"helm.sh/helm/v3/pkg/action"
+)
+
+// UpdateInstance is the action for updating a SiteWhere instance and its
+// microservices. Settings left empty keep their live value.
+type UpdateInstance struct {
+	cfg *action.Configuration
+
+	// Name of the instance
+	InstanceName string
+	// Number of replicas of the microservices, nil keeps the live value
+	Replicas *int32
+	// Registry is the docker registry of the microservices images
+	Registry string
+	// Docker image tag
+	Tag string
+	// Configuration Template
+	ConfigurationTemplate string
+	// Dataset template
+	DatasetTemplate string
+	// DebugMicroservices are the functional areas of the microservices whose
+	// debug mode is enabled
+	DebugMicroservices []string
+	// DryRun shows the changes without applying them
+	DryRun bool
+	// Rolling updates the microservices one functional area at a time,
+	// waiting for each one to be available
+	Rolling bool
+	// Order are the functional areas updated first in a rolling update,
+	// the others follow in alphabetical order
+	Order []string
+	// Timeout for each microservice to be available in a rolling update
+	Timeout time.Duration
+	// Progress is called when the status of an updated microservice changes
+	Progress ProgressFunc
+
+	pollInterval time.Duration
+}
+
+// NewUpdateInstance constructs a new *UpdateInstance
+func NewUpdateInstance(cfg *action.Configuration) *UpdateInstance {
+	return &UpdateInstance{
+		cfg:                   cfg,
+		InstanceName:          "",
+		Replicas:              nil,
+		Registry:              "",
+		Tag:                   "",
+		ConfigurationTemplate: "",
+		DatasetTemplate:       "",
+		DebugMicroservices:    nil,
+		DryRun:                false,
+		Rolling:               false,
+		Order:                 []string{instanceManagementArea},
+		Timeout:               defaultRollingUpdateTimeout,
+		Progress:              nil,
+		pollInterval:          installPollInterval,
+	}
+}
+
+// Run executes the update command, returning the changes of the specs
+func (i *UpdateInstance) Run() (*instance.UpdateSiteWhereInstance, error) {
+	if i.Replicas == nil && i.Registry == "" && i.Tag == "" && i.ConfigurationTemplate == "" && i.DatasetTemplate == "" &&
+		len(i.DebugMicroservices) == 0 {
+		return nil, errors.New("nothing to update, set the tag, registry, replicas, templates or debug microservices")
+	}
+	// Zero replicas are dropped from the specs, use scale to stop microservices
+	if i.Replicas != nil && *i.Replicas < 1 {
+		return nil, errors.New("the number of replicas must be at least 1, use `swctl scale` to stop microservices")
+	}
+	if err := i.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	client, err := ControllerClient(i.cfg)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.TODO()
+	swInstance, namespace, err := getInstance(ctx, i.cfg, client, i.InstanceName)
+	if err != nil {
+		return nil, err
+	}
+	var swMicroserviceList sitewhereiov1alpha4.SiteWhereMicroserviceList
+	if err := client.List(ctx, &swMicroserviceList, ctlcli.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	if err := i.validateDebug(swMicroserviceList.Items); err != nil {
+		return nil, err
+	}
+	result := &instance.UpdateSiteWhereInstance{
+		InstanceName: i.InstanceName,
+		Namespace:    namespace,
+		DryRun:       i.DryRun,
+	}
+	if i.Rolling {
+		clientset, err := i.cfg.KubernetesClientSet()
+		if err != nil {
+			return nil, err
+		}
+		// The instance is updated once every microservice is available
+		steps, changes, err := i.rollingUpdate(ctx, client, clientset, swMicroserviceList.Items)
+		result.Steps = steps
+		result.Changes = changes
+		if err != nil {
+			return result, err
+		}
+		changes, err = i.updateInstance(ctx, client, swInstance)
+		result.Changes = append(changes, result.Changes...)
+		return result, err
+	}
+	changes, err := i.updateInstance(ctx, client, swInstance)
+	if err != nil {
+		return result, err
+	}
+	result.Changes = append(result.Changes, changes...)
+	for idx := range swMicroserviceList.Items {
+		changes, err := i.updateMicroservice(ctx, client, &swMicroserviceList.Items[idx])
+		if err != nil {
+			return result, err
+		}
+		result.Changes = append(result.Changes, changes...)
+	}
+	return result, nil
+}
+
+func (i *UpdateInstance) updateInstance(ctx context.Context, client ctlcli.Client, swInstance *sitewhereiov1alpha4.SiteWhereInstance) ([]instance.SpecChange, error) {
+	return i.updateObject(ctx, client, swInstance, sitewhereiov1alpha4.SiteWhereInstanceKind, func(obj runtime.Object) {
+		i.updateInstanceSpec(&obj.(*sitewhereiov1alpha4.SiteWhereInstance).Spec)
+	})
+}
+
+func (i *UpdateInstance) updateMicroservice(ctx context.Context, client ctlcli.Client, swMicroservice *sitewhereiov1alpha4.SiteWhereMicroservice) ([]instance.SpecChange, error) {
+	return i.updateObject(ctx, client, swMicroservice, sitewhereiov1alpha4.SiteWhereMicroserviceKind, func(obj runtime.Object) {
+		i.updateMicroserviceSpec(&obj.(*sitewhereiov1alpha4.SiteWhereMicroservice).Spec)
+	})
+}
+
+// updateObject applies update to a copy of the live object, and patches the
+// live object with the changes unless DryRun is set
+func (i *UpdateInstance) updateObject(ctx context.Context, client ctlcli.Client, live runtime.Object, kind string, update func(runtime.Object)) ([]instance.SpecChange, error) {
+	accessor, err := meta.Accessor(live)
+	if err != nil {
+		return nil, err
+	}
+	updated := live.DeepCopyObject()
+	update(updated)
+	liveFields, err := toUnstructured(live)
+	if err != nil {
+		return nil, err
+	}
+	updatedFields, err := toUnstructured(updated)
+	if err != nil {
+		return nil, err
+	}
+	var changes []instance.SpecChange
+	diffFields("", liveFields["spec"], updatedFields["spec"], func(field string, from string, to string) {
+		changes = append(changes, instance.SpecChange{
+			Kind:  kind,
+			Name:  accessor.GetName(),
+			Field: field,
+			From:  from,
+			To:    to,
+		})
+	})
+	if len(changes) == 0 || i.DryRun {
+		return changes, nil
+	}
+	if err := client.Patch(ctx, updated, ctlcli.MergeFrom(live)); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (i *UpdateInstance) updateInstanceSpec(spec *sitewhereiov1alpha4.SiteWhereInstanceSpec) {
+	if i.ConfigurationTemplate != "" {
+		spec.ConfigurationTemplate = i.ConfigurationTemplate
+	}
+	if i.DatasetTemplate != "" {
+		spec.DatasetTemplate = i.DatasetTemplate
+	}
+	if i.Registry != "" || i.Tag != "" {
+		if spec.DockerSpec == nil {
+			spec.DockerSpec = sitewhereiov1alpha4.DefaultDockerSpec.DeepCopy()
+		}
+		i.updateDockerSpec(spec.DockerSpec)
+	}
+	for idx := range spec.Microservices {
+		i.updateMicroserviceSpec(&spec.Microservices[idx])
+	}
+}
+
+// validateDebug checks that the debug microservices exist and have debug ports
+func (i *UpdateInstance) validateDebug(microservices []sitewhereiov1alpha4.SiteWhereMicroservice) error {
+	if len(i.DebugMicroservices) == 0 {
+		return nil
+	}
+	var specs []sitewhereiov1alpha4.SiteWhereMicroserviceSpec
+	for idx := range microservices {
+		specs = append(specs, *microservices[idx].Spec.DeepCopy())
+	}
+	return enableDebug(specs, false, i.DebugMicroservices)
+}
+
+func (i *UpdateInstance) updateMicroserviceSpec(spec *sitewhereiov1alpha4.SiteWhereMicroserviceSpec) {
+	if i.Replicas != nil {
+		spec.Replicas = *i.Replicas
+	}
+	if containsString(i.DebugMicroservices, spec.FunctionalArea) && spec.Debug != nil {
+		spec.Debug.Enabled = true
+	}
+	if spec.PodSpec != nil && spec.PodSpec.DockerSpec != nil {
+		i.updateDockerSpec(spec.PodSpec.DockerSpec)
+	}
+}
+
+func (i *UpdateInstance) updateDockerSpec(spec *sitewhereiov1alpha4.DockerSpec) {
+	if i.Registry != "" {
+		spec.Registry = i.Registry
+	}
+	if i.Tag != "" {
+		spec.Tag = i.Tag
+	}
+}
+
+// diffFields calls changed for each leaf field that differs between from
+// and to. Items of lists with a functionalArea are named by it.
+func diffFields(path string, from interface{}, to interface{}, changed func(field string, from string, to string)) {
+	fromMap, fromIsMap := from.(map[string]interface{})
+	toMap, toIsMap := to.(map[string]interface{})
+	if fromIsMap && toIsMap {
+		var keys []string
+		for key := range fromMap {
+			keys = append(keys, key)
+		}
+		for key := range toMap {
+			if _, ok := fromMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			diffFields(joinField(path, key), fromMap[key], toMap[key], changed)
+		}
+		return
+	}
+	fromList, fromIsList := from.([]interface{})
+	toList, toIsList := to.([]interface{})
+	if fromIsList && toIsList && len(fromList) == len(toList) {
+		for idx := range fromList {
+			diffFields(fmt.Sprintf("%s[%s]", path, itemKey(fromList[idx], idx)), fromList[idx], toList[idx], changed)
+		}
+		return
+	}
+	fromValue, toValue := fieldValue(from), fieldValue(to)
+	if fromValue != toValue {
+		changed(path, fromValue, toValue)
+	}
+}
+
+func joinField(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return strings.Join([]string{path, key}, ".")
+}
+
+func itemKey(item interface{}, idx int) string {
+	if itemMap, ok := item.(map[string]interface{}); ok {
+		if area, ok := itemMap["functionalArea"].(string); ok && area != "" {
+			return area
+		}
+	}
+	return fmt.Sprintf("%d", idx)
+}
+
+func fieldValue(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return ""
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(value)
+		return string(data)
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,150 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctlcli "sigs.k8s.io/controller-runtime/pkg/client"
+	ctlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
+)
+
+func TestUpdateInstanceUpdateObject(t *testing.T) {
+	microserviceSpec := sitewhereiov1alpha4.SiteWhereMicroserviceSpec{
+		FunctionalArea: "device-management",
+		Replicas:       1,
+		PodSpec: &sitewhereiov1alpha4.MicroservicePodSpecification{
+			DockerSpec: &sitewhereiov1alpha4.DockerSpec{Registry: "docker.io", Repository: "sitewhere", Tag: "3.0.5"},
+		},
+	}
+	swMicroservice := &sitewhereiov1alpha4.SiteWhereMicroservice{
+		ObjectMeta: metav1.ObjectMeta{Name: "device-management", Namespace: "sitewhere"},
+		Spec:       microserviceSpec,
+	}
+	client := ctlfake.NewFakeClientWithScheme(scheme, swMicroservice.DeepCopy())
+	ctx := context.TODO()
+	dryRun := &UpdateInstance{Tag: "3.0.6", DryRun: true}
+	changes, err := dryRun.updateMicroservice(ctx, client, swMicroservice)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(changes) != 1 || changes[0].Field != "podSpec.dockerSpec.tag" || changes[0].From != "3.0.5" || changes[0].To != "3.0.6" {
+		t.Fatalf("unexpected changes %v", changes)
+	}
+
+	var live sitewhereiov1alpha4.SiteWhereMicroservice
+	key := ctlcli.ObjectKey{Name: "device-management", Namespace: "sitewhere"}
+	if err := client.Get(ctx, key, &live); err != nil {
+		t.Fatalf(err.Error())
+	}
+	replicas := int32(3)
+	u := &UpdateInstance{Tag: "3.0.6", Replicas: &replicas}
+	changes, err = u.updateMicroservice(ctx, client, &live)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %v", changes)
+	}
+	if err := client.Get(ctx, key, &live); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if live.Spec.Replicas != 3 || live.Spec.PodSpec.DockerSpec.Tag != "3.0.6" {
+		t.Fatalf("expected 3 replicas of 3.0.6, got %d of %s", live.Spec.Replicas, live.Spec.PodSpec.DockerSpec.Tag)
+	}
+}
+
+func TestDiffFields(t *testing.T) {
+	from := map[string]interface{}{
+		"dockerSpec": map[string]interface{}{"tag": "3.0.5"},
+		"microservices": []interface{}{
+			map[string]interface{}{"functionalArea": "event-sources", "replicas": 1.0},
+		},
+	}
+	to := map[string]interface{}{
+		"dockerSpec": map[string]interface{}{"tag": "3.0.6"},
+		"microservices": []interface{}{
+			map[string]interface{}{"functionalArea": "event-sources", "replicas": 2.0},
+		},
+	}
+	var fields []string
+	diffFields("", from, to, func(field string, from string, to string) {
+		fields = append(fields, field+" "+from+" "+to)
+	})
+	expected := []string{"dockerSpec.tag 3.0.5 3.0.6", "microservices[event-sources].replicas 1 2"}
+	if len(fields) != len(expected) || fields[0] != expected[0] || fields[1] != expected[1] {
+		t.Fatalf("expected %v, got %v", expected, fields)
+	}
+}
+
+func TestUpdateInstanceRunReplicas(t *testing.T) {
+	u := NewUpdateInstance(nil)
+	u.InstanceName = "sitewhere"
+	replicas := int32(0)
+	u.Replicas = &replicas
+	if _, err := u.Run(); err == nil || !strings.HasPrefix(err.Error(), "the number of replicas must be at least 1") {
+		t.Fatalf("expected the replicas to be rejected, got %v", err)
+	}
+}
+
+func TestUpdateInstanceDebug(t *testing.T) {
+	var microservices []sitewhereiov1alpha4.SiteWhereMicroservice
+	for _, spec := range debugMicroservices() {
+		microservices = append(microservices, sitewhereiov1alpha4.SiteWhereMicroservice{
+			ObjectMeta: metav1.ObjectMeta{Name: spec.FunctionalArea, Namespace: "sitewhere"},
+			Spec:       spec,
+		})
+	}
+	unknown := &UpdateInstance{DebugMicroservices: []string{"event-management"}}
+	if err := unknown.validateDebug(microservices); err == nil {
+		t.Fatalf("expected error for an unknown microservice")
+	}
+	u := &UpdateInstance{DebugMicroservices: []string{"device-management"}}
+	if err := u.validateDebug(microservices); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if microservices[1].Spec.Debug.Enabled {
+		t.Fatalf("expected the validation not to change the microservices")
+	}
+	client := ctlfake.NewFakeClientWithScheme(scheme, microservices[0].DeepCopy(), microservices[1].DeepCopy())
+	ctx := context.TODO()
+	for idx := range microservices {
+		if _, err := u.updateMicroservice(ctx, client, &microservices[idx]); err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+	for _, expected := range []struct {
+		name    string
+		enabled bool
+	}{
+		{name: "instance-management", enabled: false},
+		{name: "device-management", enabled: true},
+	} {
+		var live sitewhereiov1alpha4.SiteWhereMicroservice
+		if err := client.Get(ctx, ctlcli.ObjectKey{Name: expected.name, Namespace: "sitewhere"}, &live); err != nil {
+			t.Fatalf(err.Error())
+		}
+		if live.Spec.Debug.Enabled != expected.enabled {
+			t.Fatalf("expected debug of %s enabled %t", expected.name, expected.enabled)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/sitewhere/swctl/pkg/cli"
+	"github.com/sitewhere/swctl/pkg/install"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// Upgrade is the action for upgrading SiteWhere
+type Upgrade struct {
+	cfg *action.Configuration
+
+	settings *cli.EnvSettings
+
+	InfrastructureValues
+
+	ChartSource
+
+	// ReuseValues if true, reuse the values of the last release and merge in the new ones
+	ReuseValues bool
+	// Wait for components to be ready before return control.
+	WaitReady bool
+	// DryRun if true, shows the changes without applying them
+	DryRun bool
+	// Use verbose mode
+	Verbose bool
+}
+
+// NewUpgrade constructs a new *Upgrade
+func NewUpgrade(cfg *action.Configuration, settings *cli.EnvSettings) *Upgrade {
+	return &Upgrade{
+		cfg:                  cfg,
+		settings:             settings,
+		InfrastructureValues: newInfrastructureValues(),
+		ChartSource:          newChartSource(),
+		ReuseValues:          false,
+		WaitReady:            false,
+		DryRun:               false,
+		Verbose:              false,
+	}
+}
+
+// Run executes the upgrade command, returning the result of the upgrade
+func (i *Upgrade) Run() (*install.SiteWhereUpgrade, error) {
+	var err error
+	if err = i.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	err = i.prepareRepository(i.settings)
+	if err != nil {
+		return nil, err
+	}
+	return i.upgradeRelease()
+}
+
+func (i *Upgrade) upgradeRelease() (*install.SiteWhereUpgrade, error) {
+	actionConfig, err := newReleaseConfiguration(i.settings, i.Verbose)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := action.NewGet(actionConfig).Run(i.settings.ReleaseName)
+	if err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			return nil, errors.Errorf("release %s not found, install SiteWhere with `swctl install` first", i.settings.ReleaseName)
+		}
+		return nil, err
+	}
+
+	upgradeAction := action.NewUpgrade(actionConfig)
+	upgradeAction.Namespace = i.settings.SystemNamespace
+	upgradeAction.Version = i.HelmChartVersion
+	upgradeAction.ReuseValues = i.ReuseValues
+	upgradeAction.Wait = i.WaitReady
+	upgradeAction.DryRun = i.DryRun
+
+	if !i.ReuseValues {
+		// without the values of the last release every toggle is rendered
+		i.FlagChanged = nil
+	}
+	vals, err := i.MergeValues(getter.All(i.settings.EnvSettings))
+	if err != nil {
+		return nil, err
+	}
+
+	chartRequested, err := loadSiteWhereChart(&upgradeAction.ChartPathOptions, i.ChartPath, false, i.settings)
+	if err != nil {
+		return nil, err
+	}
+
+	if i.ReuseValues {
+		// only the flags set by the user are merged over the values of the
+		// last release, in the same way Helm does
+		vals = chartutil.CoalesceTables(vals, current.Config)
+	}
+
+	result := &install.SiteWhereUpgrade{
+		Release:              current.Name,
+		Namespace:            current.Namespace,
+		Revision:             current.Version,
+		PreviousChartVersion: current.Chart.Metadata.Version,
+		ChartVersion:         chartRequested.Metadata.Version,
+		ValueChanges:         diffValues(current.Config, vals),
+		DryRun:               i.DryRun,
+	}
+
+	if i.DryRun {
+		return result, nil
+	}
+
+	res, err := upgradeAction.Run(i.settings.ReleaseName, chartRequested, vals)
+	if err != nil {
+		return nil, err
+	}
+	result.Revision = res.Version
+	return result, nil
+}
+
+// diffValues returns the values that differ between two sets of values,
+// sorted by key
+func diffValues(previous map[string]interface{}, current map[string]interface{}) []install.ValueChange {
+	var flatPrevious = map[string]interface{}{}
+	var flatCurrent = map[string]interface{}{}
+	flattenValues("", previous, flatPrevious)
+	flattenValues("", current, flatCurrent)
+
+	var keys []string
+	for key := range flatPrevious {
+		keys = append(keys, key)
+	}
+	for key := range flatCurrent {
+		if _, ok := flatPrevious[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var result []install.ValueChange
+	for _, key := range keys {
+		previousValue, currentValue := flatPrevious[key], flatCurrent[key]
+		// stored releases decode numbers as float64, compare the rendered values
+		if fmt.Sprintf("%v", previousValue) != fmt.Sprintf("%v", currentValue) {
+			result = append(result, install.ValueChange{
+				Key:      key,
+				Previous: previousValue,
+				Value:    currentValue,
+			})
+		}
+	}
+	return result
+}
+
+func flattenValues(prefix string, vals map[string]interface{}, out map[string]interface{}) {
+	for key, value := range vals {
+		var path = key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenValues(path, nested, out)
+		} else {
+			out[path] = value
+		}
+	}
+}
@@ -0,0 +1,100 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"testing"
+
+	"github.com/sitewhere/swctl/pkg/install"
+)
+
+func TestDiffValues(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		name     string
+		previous map[string]interface{}
+		current  map[string]interface{}
+		expected []install.ValueChange
+	}{
+		{
+			name: "unchanged",
+			previous: map[string]interface{}{
+				"strimzi": map[string]interface{}{
+					"replicas": float64(1),
+				},
+			},
+			current: map[string]interface{}{
+				"strimzi": map[string]interface{}{
+					"replicas": 1,
+				},
+			},
+		},
+		{
+			name: "changed-added-removed",
+			previous: map[string]interface{}{
+				"operator": map[string]interface{}{
+					"enabled": true,
+				},
+				"influxdb": map[string]interface{}{
+					"persistence": map[string]interface{}{
+						"size": "10Gi",
+					},
+				},
+			},
+			current: map[string]interface{}{
+				"operator": map[string]interface{}{
+					"enabled": false,
+				},
+				"redis": map[string]interface{}{
+					"enabled": true,
+				},
+			},
+			expected: []install.ValueChange{
+				{Key: "influxdb.persistence.size", Previous: "10Gi", Value: nil},
+				{Key: "operator.enabled", Previous: true, Value: false},
+				{Key: "redis.enabled", Previous: nil, Value: true},
+			},
+		},
+	}
+	for _, single := range data {
+		t.Run(single.name, func(single struct {
+			name     string
+			previous map[string]interface{}
+			current  map[string]interface{}
+			expected []install.ValueChange
+		}) func(t *testing.T) {
+			return func(t *testing.T) {
+				result := diffValues(single.previous, single.current)
+				if len(single.expected) != len(result) {
+					t.Fatalf("expected %d changes, got %d changes", len(single.expected), len(result))
+				}
+				for i, r := range result {
+					e := single.expected[i]
+					if e.Key != r.Key {
+						t.Fatalf("expected key: %s got key: %s", e.Key, r.Key)
+					}
+					if e.Previous != r.Previous {
+						t.Fatalf("expected previous: %v got previous: %v", e.Previous, r.Previous)
+					}
+					if e.Value != r.Value {
+						t.Fatalf("expected value: %v got value: %v", e.Value, r.Value)
+					}
+				}
+			}
+		}(single))
+	}
+}
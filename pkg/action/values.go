@@ -0,0 +1,317 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"github.com/sitewhere/swctl/pkg/config"
+	"github.com/sitewhere/swctl/pkg/install"
+
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/getter"
+)
+
+// InfrastructureValues are the options used to build the values
+// of SiteWhere Infrastructure Helm Chart
+type InfrastructureValues struct {
+	// HelmChartVersion is the version of SiteWhere Infrastructure Helm Chart
+	HelmChartVersion string
+	// SkipTemplate indicates if we need to install SiteWhere templates
+	SkipTemplate bool
+	// SkipOperator indicates if we need to install SiteWhere Operator
+	SkipOperator bool
+	// SkipInfrastructure indicates if we need to install SiteWhere Infrastructure
+	SkipInfrastructure bool
+	// With are the infrastructure components to install, all if empty
+	With []string
+	// Without are the infrastructure components not to install
+	Without []string
+	// Minimal if true, deploy minimal infrastucure
+	Minimal bool
+	// Size is the name of the sizing preset of the infrastructure
+	Size string
+	// SizesPath is the directory of the user defined sizing presets
+	SizesPath string
+	// Profile is the name of the profile whose infrastructure toggles are used
+	Profile string
+	// ProfilesPath is the directory of the user defined profiles
+	ProfilesPath string
+	// StorageClass is the name of the storage class for the infrastructure
+	StorageClass string
+	// KafkaPVCStorageSize is the size of Kafka PVC Storage Size
+	KafkaPVCStorageSize string
+	// InfluxDBPVCStorageSize is the size of InfluxDB PVC Storage Size
+	InfluxDBPVCStorageSize string
+	// ValueOptions are the user supplied values files and --set overrides
+	ValueOptions values.Options
+	// FlagChanged reports if a flag was set by the user. When nil, all the
+	// toggles are rendered, otherwise only the ones whose flags were set.
+	FlagChanged func(name string) bool
+}
+
+func newInfrastructureValues() InfrastructureValues {
+	return InfrastructureValues{
+		HelmChartVersion:       defaultHelmChartVersion,
+		SkipTemplate:           false,
+		SkipOperator:           false,
+		SkipInfrastructure:     false,
+		With:                   []string{},
+		Without:                []string{},
+		Minimal:                false,
+		Size:                   "",
+		SizesPath:              config.GetSizingPresetsPath(),
+		Profile:                "",
+		ProfilesPath:           config.GetProfilesPath(),
+		StorageClass:           "",
+		KafkaPVCStorageSize:    "",
+		InfluxDBPVCStorageSize: "",
+		ValueOptions:           values.Options{},
+		FlagChanged:            nil,
+	}
+}
+
+// MergeValues builds the values of the SiteWhere Infrastructure Helm Chart.
+// The values of the sizing preset and the values derived from the flags are
+// deep merged, and then the user supplied values files and --set overrides
+// are merged on top of them.
+func (v *InfrastructureValues) MergeValues(p getter.Providers) (map[string]interface{}, error) {
+	if err := v.ApplyProfile(); err != nil {
+		return nil, err
+	}
+	selected, err := v.SelectedComponents()
+	if err != nil {
+		return nil, err
+	}
+	vals := map[string]interface{}{}
+	if v.Size != "" {
+		preset, err := config.LoadSizingPreset(v.SizesPath, v.Size)
+		if err != nil {
+			return nil, err
+		}
+		vals = sizingValues(preset)
+	}
+	userVals, err := v.ValueOptions.MergeValues(p)
+	if err != nil {
+		return nil, err
+	}
+	return mergeValues(mergeValues(vals, v.flagValues(selected)), userVals), nil
+}
+
+// ApplyProfile applies the infrastructure toggles of the profile. The
+// toggles set by the flags take precedence over the ones of the profile.
+func (v *InfrastructureValues) ApplyProfile() error {
+	if v.Profile == "" {
+		return nil
+	}
+	prof, err := config.LoadProfile(v.ProfilesPath, v.Profile)
+	if err != nil {
+		return err
+	}
+	infra := prof.Metadata.Infrastructure
+	v.Minimal = v.Minimal || infra.Minimal
+	if len(v.With) == 0 {
+		v.With = infra.With
+	}
+	if len(v.Without) == 0 {
+		v.Without = infra.Without
+	}
+	if v.Size == "" {
+		v.Size = infra.Size
+	}
+	return nil
+}
+
+// SelectedComponents returns the infrastructure components to install
+func (v *InfrastructureValues) SelectedComponents() (map[install.Component]bool, error) {
+	selected, err := install.SelectComponents(v.With, v.Without)
+	if err != nil {
+		return nil, err
+	}
+	if v.SkipInfrastructure {
+		return map[install.Component]bool{}, nil
+	}
+	return selected, nil
+}
+
+// changed returns true if any of the flags was set by the user
+func (v *InfrastructureValues) changed(names ...string) bool {
+	if v.FlagChanged == nil {
+		return true
+	}
+	for _, name := range names {
+		if v.FlagChanged(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *InfrastructureValues) flagValues(selected map[install.Component]bool) map[string]interface{} {
+	vals := map[string]interface{}{}
+
+	// Skip operator
+	if v.changed("skip-operator") {
+		vals = mergeValues(vals, map[string]interface{}{
+			"operator": map[string]interface{}{
+				"enabled": !v.SkipOperator,
+			},
+		})
+	}
+
+	// Skip templates
+	if v.changed("skip-templates") {
+		vals = mergeValues(vals, map[string]interface{}{
+			"templates": map[string]interface{}{
+				"enabled": !v.SkipTemplate,
+			},
+		})
+	}
+
+	// Skip infrastructure
+	if v.changed("skip-infra") {
+		vals = mergeValues(vals, map[string]interface{}{
+			"tags": map[string]interface{}{
+				"infrastructure": !v.SkipInfrastructure,
+			},
+		})
+	}
+	if v.changed("skip-infra", "with", "without", "profile") {
+		for _, info := range install.Components() {
+			vals = mergeValues(vals, map[string]interface{}{
+				string(info.Component): map[string]interface{}{
+					"enabled": selected[info.Component],
+				},
+			})
+		}
+	}
+	if v.Minimal {
+		vals = mergeValues(vals, map[string]interface{}{
+			"strimzi": map[string]interface{}{
+				"replicas": 1,
+				"isr":      1,
+			},
+		})
+	}
+
+	// set storage class
+	if v.StorageClass != "" {
+		vals = mergeValues(vals, map[string]interface{}{
+			// InfluxDB storage class
+			"influxdb": map[string]interface{}{
+				"persistence": map[string]interface{}{
+					"storageClass": v.StorageClass,
+				},
+			},
+			// Redis
+			"redis": map[string]interface{}{
+				"global": map[string]interface{}{
+					"storageClass": v.StorageClass,
+				},
+				"master": map[string]interface{}{
+					"persistence": map[string]interface{}{
+						"storageClass": v.StorageClass,
+					},
+				},
+				"slave": map[string]interface{}{
+					"persistence": map[string]interface{}{
+						"storageClass": v.StorageClass,
+					},
+				},
+			},
+			// Nifi
+			"nifi": map[string]interface{}{
+				"persistence": map[string]interface{}{
+					"storageClass": v.StorageClass,
+				},
+				"zookeeper": map[string]interface{}{
+					"global": map[string]interface{}{
+						"storageClass": v.StorageClass,
+					},
+					"persistence": map[string]interface{}{
+						"storageClass": v.StorageClass,
+					},
+				},
+			},
+			// PostgreSQL
+			"postgresql": map[string]interface{}{
+				"global": map[string]interface{}{
+					"storageClass": v.StorageClass,
+				},
+				"persistence": map[string]interface{}{
+					"storageClass": v.StorageClass,
+				},
+			},
+			// Keycloak
+			"keycloak": map[string]interface{}{
+				"postgresql": map[string]interface{}{
+					"persistence": map[string]interface{}{
+						"storageClass": v.StorageClass,
+					},
+				},
+			},
+			// Strimzi
+			"strimzi": map[string]interface{}{
+				"storage": map[string]interface{}{
+					"type":         "persistent-claim",
+					"storageClass": v.StorageClass,
+				},
+			},
+		})
+	}
+
+	// KafkaPVCStorageSize
+	if v.KafkaPVCStorageSize != "" {
+		vals = mergeValues(vals, map[string]interface{}{
+			"strimzi": map[string]interface{}{
+				"storage": map[string]interface{}{
+					"size": v.KafkaPVCStorageSize,
+				},
+			},
+		})
+	}
+
+	// InfluxDBPVCStorageSize
+	if v.InfluxDBPVCStorageSize != "" {
+		vals = mergeValues(vals, map[string]interface{}{
+			"influxdb": map[string]interface{}{
+				"persistence": map[string]interface{}{
+					"size": v.InfluxDBPVCStorageSize,
+				},
+			},
+		})
+	}
+	return vals
+}
+
+// mergeValues deep merges b into a, values in b take precedence.
+func mergeValues(a, b map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(a))
+	for k, v := range a {
+		out[k] = v
+	}
+	for k, v := range b {
+		if v, ok := v.(map[string]interface{}); ok {
+			if bv, ok := out[k]; ok {
+				if bv, ok := bv.(map[string]interface{}); ok {
+					out[k] = mergeValues(bv, v)
+					continue
+				}
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,193 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"testing"
+
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/getter"
+)
+
+func TestInfrastructureValuesMergeValues(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		name     string
+		values   InfrastructureValues
+		expected map[string]interface{}
+	}{
+		{
+			name: "storage-class-skip-infra",
+			values: InfrastructureValues{
+				SkipInfrastructure: true,
+				StorageClass:       "fast",
+			},
+			expected: map[string]interface{}{
+				"influxdb.enabled":                  false,
+				"influxdb.persistence.storageClass": "fast",
+				"strimzi.enabled":                   false,
+				"strimzi.storage.storageClass":      "fast",
+			},
+		},
+		{
+			name: "kafka-pvc-size-storage-class",
+			values: InfrastructureValues{
+				Minimal:             true,
+				StorageClass:        "fast",
+				KafkaPVCStorageSize: "20Gi",
+			},
+			expected: map[string]interface{}{
+				"strimzi.enabled":              true,
+				"strimzi.replicas":             1,
+				"strimzi.storage.storageClass": "fast",
+				"strimzi.storage.size":         "20Gi",
+			},
+		},
+		{
+			name: "without-managed-components",
+			values: InfrastructureValues{
+				Without: []string{"postgresql", "kafka"},
+			},
+			expected: map[string]interface{}{
+				"postgresql.enabled": false,
+				"strimzi.enabled":    false,
+				"keycloak.enabled":   true,
+				"redis.enabled":      true,
+				"mosquitto.enabled":  true,
+			},
+		},
+		{
+			name: "with-components",
+			values: InfrastructureValues{
+				With: []string{"keycloak", "redis", "mosquitto"},
+			},
+			expected: map[string]interface{}{
+				"postgresql.enabled": false,
+				"influxdb.enabled":   false,
+				"keycloak.enabled":   true,
+				"redis.enabled":      true,
+				"mosquitto.enabled":  true,
+			},
+		},
+		{
+			name: "size-preset",
+			values: InfrastructureValues{
+				Size: "medium",
+			},
+			expected: map[string]interface{}{
+				"strimzi.replicas":                    3,
+				"strimzi.isr":                         2,
+				"strimzi.storage.size":                "50Gi",
+				"strimzi.zookeeper.replicas":          3,
+				"strimzi.resources.limits.memory":     "4Gi",
+				"postgresql.persistence.size":         "20Gi",
+				"redis.cluster.slaveCount":            3,
+				"redis.master.resources.requests.cpu": "250m",
+				"keycloak.replicas":                   1,
+			},
+		},
+		{
+			name: "size-preset-overrides",
+			values: InfrastructureValues{
+				Size:                "large",
+				Minimal:             true,
+				KafkaPVCStorageSize: "20Gi",
+				ValueOptions: values.Options{
+					Values: []string{"keycloak.replicas=3"},
+				},
+			},
+			expected: map[string]interface{}{
+				"strimzi.replicas":                     1,
+				"strimzi.isr":                          1,
+				"strimzi.storage.size":                 "20Gi",
+				"postgresql.replication.slaveReplicas": 1,
+				"keycloak.replicas":                    int64(3),
+			},
+		},
+		{
+			name: "set-overrides",
+			values: InfrastructureValues{
+				StorageClass: "fast",
+				ValueOptions: values.Options{
+					Values:       []string{"keycloak.replicas=2", "redis.enabled=false"},
+					StringValues: []string{"strimzi.storage.storageClass=slow"},
+				},
+			},
+			expected: map[string]interface{}{
+				"keycloak.enabled":             true,
+				"keycloak.replicas":            int64(2),
+				"redis.enabled":                false,
+				"strimzi.storage.storageClass": "slow",
+			},
+		},
+		{
+			name: "changed-flags-only",
+			values: InfrastructureValues{
+				SkipOperator: true,
+				StorageClass: "fast",
+				FlagChanged: func(name string) bool {
+					return name == "skip-operator" || name == "storage-class"
+				},
+			},
+			expected: map[string]interface{}{
+				"operator.enabled":             false,
+				"templates.enabled":            nil,
+				"tags.infrastructure":          nil,
+				"strimzi.enabled":              nil,
+				"strimzi.storage.storageClass": "fast",
+			},
+		},
+	}
+	for _, single := range data {
+		t.Run(single.name, func(single struct {
+			name     string
+			values   InfrastructureValues
+			expected map[string]interface{}
+		}) func(t *testing.T) {
+			return func(t *testing.T) {
+				result, err := single.values.MergeValues(getter.Providers{})
+				if err != nil {
+					t.Fatalf(err.Error())
+				}
+				var flat = map[string]interface{}{}
+				flattenValues("", result, flat)
+				for key, expected := range single.expected {
+					if flat[key] != expected {
+						t.Fatalf("expected %s: %v got %s: %v", key, expected, key, flat[key])
+					}
+				}
+			}
+		}(single))
+	}
+}
+
+func TestInfrastructureValuesApplyProfile(t *testing.T) {
+	v := InfrastructureValues{
+		Profile: "minimal",
+		Size:    "large",
+	}
+	if err := v.ApplyProfile(); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if !v.Minimal || v.Size != "large" {
+		t.Fatalf("expected minimal infrastructure of size large, got minimal %t size %s", v.Minimal, v.Size)
+	}
+	v.Profile = "unknown"
+	if err := v.ApplyProfile(); err == nil {
+		t.Fatalf("expected error for unknown profile")
+	}
+}
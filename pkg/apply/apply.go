@@ -0,0 +1,47 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+// Result is the result of applying an object
+type Result string
+
+const (
+	// Created the object did not exist and was created
+	Created Result = "created"
+	// Configured the object existed and was updated
+	Configured Result = "configured"
+	// Unchanged the object existed and was up to date
+	Unchanged Result = "unchanged"
+)
+
+// AppliedObject describe an applied object
+type AppliedObject struct {
+	// Kind of the object
+	Kind string `json:"kind"`
+	// Name of the object
+	Name string `json:"name"`
+	// Namespace of the object, empty for cluster-scoped objects
+	Namespace string `json:"namespace,omitempty"`
+	// Result of applying the object
+	Result Result `json:"result"`
+}
+
+// SiteWhereApply describe the objects applied from a file.
+type SiteWhereApply struct {
+	// Objects applied, in file order
+	Objects []AppliedObject `json:"objects"`
+}
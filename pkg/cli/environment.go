@@ -0,0 +1,130 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package cli describes the settings of swctl.
+//
+// The settings of the SiteWhere installation are resolved, from lowest to
+// highest precedence, from the swctl defaults, the swctl configuration file,
+// the SWCTL_* environment variables and the command line flags.
+package cli
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/spf13/pflag"
+
+	"github.com/sitewhere/swctl/pkg/config"
+
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+const (
+	// DefaultNamespace is the default SiteWhere System Namespace
+	DefaultNamespace = "sitewhere-system"
+	// DefaultReleaseName is the default name of the SiteWhere Infrastructure release
+	DefaultReleaseName = "sitewhere"
+	// DefaultChartName is the default name of the SiteWhere Infrastructure chart
+	DefaultChartName = "sitewhere-infrastructure"
+	// DefaultRepositoryName is the default name of the SiteWhere chart repository
+	DefaultRepositoryName = "sitewhere"
+	// DefaultRepositoryURL is the default URL of the SiteWhere chart repository
+	DefaultRepositoryURL = "https://sitewhere.io/helm-charts"
+)
+
+// EnvSettings describes the settings of swctl and of the Helm client it uses.
+type EnvSettings struct {
+	*cli.EnvSettings
+
+	// ConfigFile is the path to the swctl configuration file
+	ConfigFile string
+	// SystemNamespace is the SiteWhere System Namespace
+	SystemNamespace string
+	// ReleaseName is the name of the SiteWhere Infrastructure release
+	ReleaseName string
+	// ChartName is the name of the SiteWhere Infrastructure chart
+	ChartName string
+	// Repository is the chart repository of the SiteWhere Infrastructure chart
+	Repository config.Repository
+	// Infrastructure is the external infrastructure used by the instances
+	Infrastructure config.ExternalInfrastructure
+}
+
+// New returns the settings resolved from the defaults, the configuration
+// file and the environment variables.
+func New() (*EnvSettings, error) {
+	env := &EnvSettings{
+		EnvSettings: cli.New(),
+		ConfigFile:  envOr("SWCTL_CONFIG", config.GetCLIConfigPath()),
+	}
+	conf, err := config.LoadCLIConfiguration(env.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	env.SystemNamespace = envOr("SWCTL_NAMESPACE", valueOr(conf.Namespace, DefaultNamespace))
+	env.ReleaseName = envOr("SWCTL_RELEASE", valueOr(conf.Release, DefaultReleaseName))
+	env.ChartName = envOr("SWCTL_CHART", valueOr(conf.Chart, DefaultChartName))
+	env.Repository = config.Repository{
+		Name:                  envOr("SWCTL_REPO_NAME", valueOr(conf.Repository.Name, DefaultRepositoryName)),
+		URL:                   envOr("SWCTL_REPO_URL", valueOr(conf.Repository.URL, DefaultRepositoryURL)),
+		Username:              envOr("SWCTL_REPO_USERNAME", conf.Repository.Username),
+		Password:              envOr("SWCTL_REPO_PASSWORD", conf.Repository.Password),
+		CAFile:                envOr("SWCTL_REPO_CA_FILE", conf.Repository.CAFile),
+		CertFile:              envOr("SWCTL_REPO_CERT_FILE", conf.Repository.CertFile),
+		KeyFile:               envOr("SWCTL_REPO_KEY_FILE", conf.Repository.KeyFile),
+		InsecureSkipTLSVerify: envBoolOr("SWCTL_REPO_INSECURE_SKIP_TLS_VERIFY", conf.Repository.InsecureSkipTLSVerify),
+	}
+	env.Infrastructure = conf.Infrastructure
+	return env, nil
+}
+
+// AddFlags binds flags to the given flagset.
+func (s *EnvSettings) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&s.SystemNamespace, "system-namespace", s.SystemNamespace, "SiteWhere System Namespace.")
+	fs.StringVar(&s.ReleaseName, "release", s.ReleaseName, "Name of the SiteWhere Infrastructure release.")
+	fs.StringVar(&s.ChartName, "chart", s.ChartName, "Name of the SiteWhere Infrastructure chart.")
+	fs.StringVar(&s.Repository.Name, "repo-name", s.Repository.Name, "Name of the SiteWhere chart repository.")
+	fs.StringVar(&s.Repository.URL, "repo-url", s.Repository.URL, "URL of the SiteWhere chart repository.")
+	fs.StringVar(&s.Repository.Username, "repo-username", s.Repository.Username, "Chart repository username.")
+	fs.StringVar(&s.Repository.Password, "repo-password", s.Repository.Password, "Chart repository password.")
+	fs.StringVar(&s.Repository.CAFile, "repo-ca-file", s.Repository.CAFile, "Verify the chart repository certificate using this CA bundle.")
+	fs.StringVar(&s.Repository.CertFile, "repo-cert-file", s.Repository.CertFile, "Identify to the chart repository using this SSL certificate file.")
+	fs.StringVar(&s.Repository.KeyFile, "repo-key-file", s.Repository.KeyFile, "Identify to the chart repository using this SSL key file.")
+	fs.BoolVar(&s.Repository.InsecureSkipTLSVerify, "repo-insecure-skip-tls-verify", s.Repository.InsecureSkipTLSVerify, "Skip TLS certificate checks of the chart repository.")
+}
+
+func envOr(name, def string) string {
+	if v, ok := os.LookupEnv(name); ok {
+		return v
+	}
+	return def
+}
+
+func envBoolOr(name string, def bool) bool {
+	if v, ok := os.LookupEnv(name); ok {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return def
+}
+
+func valueOr(value, def string) string {
+	if value != "" {
+		return value
+	}
+	return def
+}
@@ -0,0 +1,141 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestEnvSettings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "swctl-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	configFile := filepath.Join(dir, "config.yaml")
+	err = ioutil.WriteFile(configFile, []byte("namespace: sitewhere-a\nrelease: sitewhere-a\nrepository:\n  url: https://mirror.example.com/charts\n  username: user\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []struct {
+		name      string
+		env       map[string]string
+		args      []string
+		namespace string
+		release   string
+		chart     string
+		repoURL   string
+	}{
+		{
+			name:      "defaults",
+			env:       map[string]string{"SWCTL_CONFIG": filepath.Join(dir, "missing.yaml")},
+			namespace: DefaultNamespace,
+			release:   DefaultReleaseName,
+			chart:     DefaultChartName,
+			repoURL:   DefaultRepositoryURL,
+		},
+		{
+			name:      "config-file",
+			env:       map[string]string{"SWCTL_CONFIG": configFile},
+			namespace: "sitewhere-a",
+			release:   "sitewhere-a",
+			chart:     DefaultChartName,
+			repoURL:   "https://mirror.example.com/charts",
+		},
+		{
+			name:      "env-overrides-config-file",
+			env:       map[string]string{"SWCTL_CONFIG": configFile, "SWCTL_NAMESPACE": "sitewhere-b", "SWCTL_CHART": "mirror-chart"},
+			namespace: "sitewhere-b",
+			release:   "sitewhere-a",
+			chart:     "mirror-chart",
+			repoURL:   "https://mirror.example.com/charts",
+		},
+		{
+			name:      "flags-override-env",
+			env:       map[string]string{"SWCTL_CONFIG": configFile, "SWCTL_NAMESPACE": "sitewhere-b"},
+			args:      []string{"--system-namespace", "sitewhere-c", "--release", "sitewhere-c", "--repo-url", "https://other.example.com"},
+			namespace: "sitewhere-c",
+			release:   "sitewhere-c",
+			chart:     DefaultChartName,
+			repoURL:   "https://other.example.com",
+		},
+	}
+	for _, single := range data {
+		t.Run(single.name, func(single struct {
+			name      string
+			env       map[string]string
+			args      []string
+			namespace string
+			release   string
+			chart     string
+			repoURL   string
+		}) func(t *testing.T) {
+			return func(t *testing.T) {
+				defer resetEnv()()
+				for k, v := range single.env {
+					os.Setenv(k, v)
+				}
+				settings, err := New()
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+				settings.AddFlags(fs)
+				if err = fs.Parse(single.args); err != nil {
+					t.Fatal(err)
+				}
+				if settings.SystemNamespace != single.namespace {
+					t.Errorf("expected namespace %q, got %q", single.namespace, settings.SystemNamespace)
+				}
+				if settings.ReleaseName != single.release {
+					t.Errorf("expected release %q, got %q", single.release, settings.ReleaseName)
+				}
+				if settings.ChartName != single.chart {
+					t.Errorf("expected chart %q, got %q", single.chart, settings.ChartName)
+				}
+				if settings.Repository.URL != single.repoURL {
+					t.Errorf("expected repository URL %q, got %q", single.repoURL, settings.Repository.URL)
+				}
+			}
+		}(single))
+	}
+}
+
+func resetEnv() func() {
+	var names = []string{"SWCTL_CONFIG", "SWCTL_NAMESPACE", "SWCTL_RELEASE", "SWCTL_CHART", "SWCTL_REPO_URL"}
+	orig := map[string]string{}
+	for _, name := range names {
+		if v, ok := os.LookupEnv(name); ok {
+			orig[name] = v
+		}
+	}
+	return func() {
+		for _, name := range names {
+			if v, ok := orig[name]; ok {
+				os.Setenv(name, v)
+			} else {
+				os.Unsetenv(name)
+			}
+		}
+	}
+}
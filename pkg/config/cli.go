@@ -0,0 +1,200 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// CLIConfiguration is the configuration file of SiteWhere Control CLI.
+// Empty values use the swctl defaults.
+type CLIConfiguration struct {
+	// Namespace is the SiteWhere System Namespace
+	Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	// Release is the name of the SiteWhere Infrastructure Helm release
+	Release string `yaml:"release,omitempty" json:"release,omitempty"`
+	// Chart is the name of the SiteWhere Infrastructure Helm chart
+	Chart string `yaml:"chart,omitempty" json:"chart,omitempty"`
+	// Repository is the Helm chart repository of the SiteWhere Infrastructure chart
+	Repository Repository `yaml:"repository,omitempty" json:"repository,omitempty"`
+	// Infrastructure is the external infrastructure used by the instances
+	Infrastructure ExternalInfrastructure `yaml:"infrastructure,omitempty" json:"infrastructure,omitempty"`
+}
+
+// Repository is a Helm chart repository
+type Repository struct {
+	// Name of the repository
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// URL of the repository
+	URL string `yaml:"url,omitempty" json:"url,omitempty"`
+	// Username for basic authentication
+	Username string `yaml:"username,omitempty" json:"username,omitempty"`
+	// Password for basic authentication
+	Password string `yaml:"password,omitempty" json:"password,omitempty"`
+	// CAFile is the certificate authority file used to verify the repository
+	CAFile string `yaml:"caFile,omitempty" json:"caFile,omitempty"`
+	// CertFile is the client certificate file
+	CertFile string `yaml:"certFile,omitempty" json:"certFile,omitempty"`
+	// KeyFile is the client key file
+	KeyFile string `yaml:"keyFile,omitempty" json:"keyFile,omitempty"`
+	// InsecureSkipTLSVerify skips the verification of the repository certificate
+	InsecureSkipTLSVerify bool `yaml:"insecureSkipTLSVerify,omitempty" json:"insecureSkipTLSVerify,omitempty"`
+}
+
+// GetCLIConfigPath returns the path of the SiteWhere Control CLI configuration file.
+func GetCLIConfigPath() string {
+	return filepath.FromSlash(GetConfigHome() + "/config.yaml")
+}
+
+// LoadCLIConfiguration loads the CLI configuration file. If the file does
+// not exist it returns an empty configuration.
+func LoadCLIConfiguration(path string) (*CLIConfiguration, error) {
+	var cfg CLIConfiguration
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &cfg, nil
+		}
+		return nil, err
+	}
+	if err = yaml.Unmarshal(content, &cfg); err != nil {
+		return nil, errors.Wrapf(err, "parsing swctl configuration file %s", path)
+	}
+	return &cfg, nil
+}
+
+// SaveCLIConfiguration saves the CLI configuration file.
+func SaveCLIConfiguration(path string, cfg *CLIConfiguration) error {
+	content, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil && !os.IsExist(err) {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0600)
+}
+
+// ExternalInfrastructure are the backing services, running outside of
+// SiteWhere Infrastructure release, used by the instances. A service without
+// host uses the one installed by swctl.
+type ExternalInfrastructure struct {
+	// Kafka cluster
+	Kafka ServiceEndpoint `yaml:"kafka,omitempty" json:"kafka,omitempty"`
+	// PostgreSQL database
+	PostgreSQL ServiceEndpoint `yaml:"postgresql,omitempty" json:"postgresql,omitempty"`
+	// InfluxDB time series database
+	InfluxDB ServiceEndpoint `yaml:"influxdb,omitempty" json:"influxdb,omitempty"`
+	// Redis key-value store
+	Redis ServiceEndpoint `yaml:"redis,omitempty" json:"redis,omitempty"`
+	// Keycloak identity provider
+	Keycloak ServiceEndpoint `yaml:"keycloak,omitempty" json:"keycloak,omitempty"`
+}
+
+// ServiceEndpoint is the address and credentials of a backing service
+type ServiceEndpoint struct {
+	// Host name of the service
+	Host string `yaml:"host,omitempty" json:"host,omitempty"`
+	// Port of the service
+	Port int32 `yaml:"port,omitempty" json:"port,omitempty"`
+	// Username used to connect to the service
+	Username string `yaml:"username,omitempty" json:"username,omitempty"`
+	// Password used to connect to the service
+	Password string `yaml:"password,omitempty" json:"password,omitempty"`
+	// Database name, for the services that have one
+	Database string `yaml:"database,omitempty" json:"database,omitempty"`
+}
+
+// DefaultKeycloak is the Keycloak installed by swctl
+var DefaultKeycloak = ServiceEndpoint{
+	Host:     "sitewhere-keycloak-http",
+	Port:     80,
+	Username: "sitewhere",
+	Password: "sitewhere",
+}
+
+// Default ports of the external services
+const (
+	// DefaultKafkaPort is the default port of Kafka
+	DefaultKafkaPort int32 = 9092
+	// DefaultPostgreSQLPort is the default port of PostgreSQL
+	DefaultPostgreSQLPort int32 = 5432
+	// DefaultInfluxDBPort is the default port of InfluxDB
+	DefaultInfluxDBPort int32 = 8086
+	// DefaultRedisPort is the default port of Redis
+	DefaultRedisPort int32 = 6379
+)
+
+// withDefaultPort returns the endpoint with the default port if the
+// service is configured without port
+func (s ServiceEndpoint) withDefaultPort(port int32) ServiceEndpoint {
+	if s.Host != "" && s.Port == 0 {
+		s.Port = port
+	}
+	return s
+}
+
+// Configured returns true if any external service is configured
+func (e *ExternalInfrastructure) Configured() bool {
+	return e.Kafka.Host != "" || e.PostgreSQL.Host != "" || e.InfluxDB.Host != "" ||
+		e.Redis.Host != "" || e.Keycloak.Host != ""
+}
+
+// KafkaEndpoint returns the configured Kafka, with the default port if not set
+func (e *ExternalInfrastructure) KafkaEndpoint() ServiceEndpoint {
+	return e.Kafka.withDefaultPort(DefaultKafkaPort)
+}
+
+// PostgreSQLEndpoint returns the configured PostgreSQL, with the default port if not set
+func (e *ExternalInfrastructure) PostgreSQLEndpoint() ServiceEndpoint {
+	return e.PostgreSQL.withDefaultPort(DefaultPostgreSQLPort)
+}
+
+// InfluxDBEndpoint returns the configured InfluxDB, with the default port if not set
+func (e *ExternalInfrastructure) InfluxDBEndpoint() ServiceEndpoint {
+	return e.InfluxDB.withDefaultPort(DefaultInfluxDBPort)
+}
+
+// RedisEndpoint returns the configured Redis, with the default port if not set
+func (e *ExternalInfrastructure) RedisEndpoint() ServiceEndpoint {
+	return e.Redis.withDefaultPort(DefaultRedisPort)
+}
+
+// KeycloakEndpoint returns the configured Keycloak, using the values of
+// the Keycloak installed by swctl for the missing ones
+func (e *ExternalInfrastructure) KeycloakEndpoint() ServiceEndpoint {
+	keycloak := e.Keycloak
+	if keycloak.Host == "" {
+		keycloak.Host = DefaultKeycloak.Host
+	}
+	if keycloak.Port == 0 {
+		keycloak.Port = DefaultKeycloak.Port
+	}
+	if keycloak.Username == "" {
+		keycloak.Username = DefaultKeycloak.Username
+	}
+	if keycloak.Password == "" {
+		keycloak.Password = DefaultKeycloak.Password
+	}
+	return keycloak
+}
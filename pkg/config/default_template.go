@@ -73,24 +73,7 @@ const defaultTemplate string = `microservices:
     - name: sitewhere.config.product.id
       value: {{ .InstanceName }}
       valuefrom: null
-    - name: sitewhere.config.keycloak.service.name
-      value: sitewhere-keycloak-http
-      valuefrom: null
-    - name: sitewhere.config.keycloak.api.port
-      value: "80"
-      valuefrom: null
-    - name: sitewhere.config.keycloak.realm
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.realm
-      value: master
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.username
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.password
-      value: sitewhere
-      valuefrom: null
+{{- template "sitewhere.infrastructure" . }}
     - name: sitewhere.config.keycloak.oidc.secret
       value: ""
       valuefrom:
@@ -203,24 +186,7 @@ const defaultTemplate string = `microservices:
     - name: sitewhere.config.product.id
       value: {{ .InstanceName }}
       valuefrom: null
-    - name: sitewhere.config.keycloak.service.name
-      value: sitewhere-keycloak-http
-      valuefrom: null
-    - name: sitewhere.config.keycloak.api.port
-      value: "80"
-      valuefrom: null
-    - name: sitewhere.config.keycloak.realm
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.realm
-      value: master
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.username
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.password
-      value: sitewhere
-      valuefrom: null
+{{- template "sitewhere.infrastructure" . }}
     - name: sitewhere.config.keycloak.oidc.secret
       value: ""
       valuefrom:
@@ -333,24 +299,7 @@ const defaultTemplate string = `microservices:
     - name: sitewhere.config.product.id
       value: {{ .InstanceName }}
       valuefrom: null
-    - name: sitewhere.config.keycloak.service.name
-      value: sitewhere-keycloak-http
-      valuefrom: null
-    - name: sitewhere.config.keycloak.api.port
-      value: "80"
-      valuefrom: null
-    - name: sitewhere.config.keycloak.realm
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.realm
-      value: master
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.username
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.password
-      value: sitewhere
-      valuefrom: null
+{{- template "sitewhere.infrastructure" . }}
     - name: sitewhere.config.keycloak.oidc.secret
       value: ""
       valuefrom:
@@ -463,24 +412,7 @@ const defaultTemplate string = `microservices:
     - name: sitewhere.config.product.id
       value: {{ .InstanceName }}
       valuefrom: null
-    - name: sitewhere.config.keycloak.service.name
-      value: sitewhere-keycloak-http
-      valuefrom: null
-    - name: sitewhere.config.keycloak.api.port
-      value: "80"
-      valuefrom: null
-    - name: sitewhere.config.keycloak.realm
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.realm
-      value: master
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.username
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.password
-      value: sitewhere
-      valuefrom: null
+{{- template "sitewhere.infrastructure" . }}
     - name: sitewhere.config.keycloak.oidc.secret
       value: ""
       valuefrom:
@@ -593,24 +525,7 @@ const defaultTemplate string = `microservices:
     - name: sitewhere.config.product.id
       value: {{ .InstanceName }}
       valuefrom: null
-    - name: sitewhere.config.keycloak.service.name
-      value: sitewhere-keycloak-http
-      valuefrom: null
-    - name: sitewhere.config.keycloak.api.port
-      value: "80"
-      valuefrom: null
-    - name: sitewhere.config.keycloak.realm
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.realm
-      value: master
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.username
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.password
-      value: sitewhere
-      valuefrom: null
+{{- template "sitewhere.infrastructure" . }}
     - name: sitewhere.config.keycloak.oidc.secret
       value: ""
       valuefrom:
@@ -723,24 +638,7 @@ const defaultTemplate string = `microservices:
     - name: sitewhere.config.product.id
       value: {{ .InstanceName }}
       valuefrom: null
-    - name: sitewhere.config.keycloak.service.name
-      value: sitewhere-keycloak-http
-      valuefrom: null
-    - name: sitewhere.config.keycloak.api.port
-      value: "80"
-      valuefrom: null
-    - name: sitewhere.config.keycloak.realm
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.realm
-      value: master
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.username
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.password
-      value: sitewhere
-      valuefrom: null
+{{- template "sitewhere.infrastructure" . }}
     - name: sitewhere.config.keycloak.oidc.secret
       value: ""
       valuefrom:
@@ -853,24 +751,7 @@ const defaultTemplate string = `microservices:
     - name: sitewhere.config.product.id
       value: {{ .InstanceName }}
       valuefrom: null
-    - name: sitewhere.config.keycloak.service.name
-      value: sitewhere-keycloak-http
-      valuefrom: null
-    - name: sitewhere.config.keycloak.api.port
-      value: "80"
-      valuefrom: null
-    - name: sitewhere.config.keycloak.realm
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.realm
-      value: master
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.username
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.password
-      value: sitewhere
-      valuefrom: null
+{{- template "sitewhere.infrastructure" . }}
     - name: sitewhere.config.keycloak.oidc.secret
       value: ""
       valuefrom:
@@ -983,24 +864,7 @@ const defaultTemplate string = `microservices:
     - name: sitewhere.config.product.id
       value: {{ .InstanceName }}
       valuefrom: null
-    - name: sitewhere.config.keycloak.service.name
-      value: sitewhere-keycloak-http
-      valuefrom: null
-    - name: sitewhere.config.keycloak.api.port
-      value: "80"
-      valuefrom: null
-    - name: sitewhere.config.keycloak.realm
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.realm
-      value: master
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.username
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.password
-      value: sitewhere
-      valuefrom: null
+{{- template "sitewhere.infrastructure" . }}
     - name: sitewhere.config.keycloak.oidc.secret
       value: ""
       valuefrom:
@@ -1113,24 +977,7 @@ const defaultTemplate string = `microservices:
     - name: sitewhere.config.product.id
       value: {{ .InstanceName }}
       valuefrom: null
-    - name: sitewhere.config.keycloak.service.name
-      value: sitewhere-keycloak-http
-      valuefrom: null
-    - name: sitewhere.config.keycloak.api.port
-      value: "80"
-      valuefrom: null
-    - name: sitewhere.config.keycloak.realm
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.realm
-      value: master
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.username
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.password
-      value: sitewhere
-      valuefrom: null
+{{- template "sitewhere.infrastructure" . }}
     - name: sitewhere.config.keycloak.oidc.secret
       value: ""
       valuefrom:
@@ -1248,24 +1095,7 @@ const defaultTemplate string = `microservices:
     - name: sitewhere.config.product.id
       value: {{ .InstanceName }}
       valuefrom: null
-    - name: sitewhere.config.keycloak.service.name
-      value: sitewhere-keycloak-http
-      valuefrom: null
-    - name: sitewhere.config.keycloak.api.port
-      value: "80"
-      valuefrom: null
-    - name: sitewhere.config.keycloak.realm
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.realm
-      value: master
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.username
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.password
-      value: sitewhere
-      valuefrom: null
+{{- template "sitewhere.infrastructure" . }}
     - name: sitewhere.config.keycloak.oidc.secret
       value: ""
       valuefrom:
@@ -1523,24 +1353,7 @@ const defaultTemplate string = `microservices:
     - name: sitewhere.config.product.id
       value: {{ .InstanceName }}
       valuefrom: null
-    - name: sitewhere.config.keycloak.service.name
-      value: sitewhere-keycloak-http
-      valuefrom: null
-    - name: sitewhere.config.keycloak.api.port
-      value: "80"
-      valuefrom: null
-    - name: sitewhere.config.keycloak.realm
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.realm
-      value: master
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.username
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.password
-      value: sitewhere
-      valuefrom: null
+{{- template "sitewhere.infrastructure" . }}
     - name: sitewhere.config.keycloak.oidc.secret
       value: ""
       valuefrom:
@@ -1653,24 +1466,7 @@ const defaultTemplate string = `microservices:
     - name: sitewhere.config.product.id
       value: {{ .InstanceName }}
       valuefrom: null
-    - name: sitewhere.config.keycloak.service.name
-      value: sitewhere-keycloak-http
-      valuefrom: null
-    - name: sitewhere.config.keycloak.api.port
-      value: "80"
-      valuefrom: null
-    - name: sitewhere.config.keycloak.realm
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.realm
-      value: master
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.username
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.password
-      value: sitewhere
-      valuefrom: null
+{{- template "sitewhere.infrastructure" . }}
     - name: sitewhere.config.keycloak.oidc.secret
       value: ""
       valuefrom:
@@ -1783,24 +1579,7 @@ const defaultTemplate string = `microservices:
     - name: sitewhere.config.product.id
       value: {{ .InstanceName }}
       valuefrom: null
-    - name: sitewhere.config.keycloak.service.name
-      value: sitewhere-keycloak-http
-      valuefrom: null
-    - name: sitewhere.config.keycloak.api.port
-      value: "80"
-      valuefrom: null
-    - name: sitewhere.config.keycloak.realm
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.realm
-      value: master
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.username
-      value: sitewhere
-      valuefrom: null
-    - name: sitewhere.config.keycloak.master.password
-      value: sitewhere
-      valuefrom: null
+{{- template "sitewhere.infrastructure" . }}
     - name: sitewhere.config.keycloak.oidc.secret
       value: ""
       valuefrom:
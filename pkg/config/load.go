@@ -17,9 +17,11 @@
 package config
 
 import (
-	"github.com/sitewhere/swctl/pkg/install/profile"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+
+	"github.com/sitewhere/swctl/pkg/install/profile"
 )
 
 // LoadConfigurationTemplate loads the configuration template from
@@ -27,13 +29,7 @@ import (
 // it returns the error ErrNotFound
 func LoadConfigurationTemplate(placeHolder *PlaceHolder, prof profile.SiteWhereProfile) (string, error) {
 
-	var configPath string
-
-	if prof == profile.Minimal {
-		configPath = GetMinimalConfigPath()
-	} else {
-		configPath = GetConfigPath()
-	}
+	configPath := configurationTemplatePath(prof)
 	f, err := os.Open(configPath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -51,12 +47,39 @@ func LoadConfigurationTemplate(placeHolder *PlaceHolder, prof profile.SiteWhereP
 	return string(content), nil
 }
 
+// configurationTemplatePath returns the path of the saved configuration
+// template of the profile
+func configurationTemplatePath(prof profile.SiteWhereProfile) string {
+	if prof == profile.Minimal {
+		return GetMinimalConfigPath()
+	}
+	return GetConfigPath()
+}
+
+// OutdatedConfigurationTemplate returns the file of the configuration
+// template used by the profile, and true if the template does not render
+// the infrastructure of the microservices because an older swctl saved it.
+func OutdatedConfigurationTemplate(p *Profile) (string, bool) {
+	if p.Template != "" {
+		return filepath.Join(GetProfilesPath(), p.Name+".yaml"), !UsesInfrastructureTemplate(p.Template)
+	}
+	content, err := LoadConfigurationTemplate(nil, p.Metadata.Base)
+	if err != nil {
+		return "", false
+	}
+	return configurationTemplatePath(p.Metadata.Base), !UsesInfrastructureTemplate(content)
+}
+
 // LoadConfigurationOrDefault loads the configuration from
 // ~/swctl/config file or load the default configuration
-func LoadConfigurationOrDefault(placeHolder *PlaceHolder, profile profile.SiteWhereProfile) (*Configuration, error) {
-	templateContext, err := LoadConfigurationTemplate(placeHolder, profile)
+func LoadConfigurationOrDefault(placeHolder *PlaceHolder, prof profile.SiteWhereProfile) (*Configuration, error) {
+	templateContext, err := LoadConfigurationTemplate(placeHolder, prof)
 	if err != nil {
-		templateContext = defaultTemplate
+		if prof == profile.Minimal {
+			templateContext = minimalTemplate
+		} else {
+			templateContext = defaultTemplate
+		}
 	}
 	return FromTemplate(templateContext, placeHolder)
 }
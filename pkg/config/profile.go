@@ -0,0 +1,211 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/sitewhere/swctl/pkg/install/profile"
+
+	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
+)
+
+// Profile is a named profile of SiteWhere. A user defined profile is a
+// configuration template, stored as NAME.yaml in the profiles directory,
+// with its metadata under the profile key.
+type Profile struct {
+	// Name of the profile
+	Name string `json:"name"`
+	// BuiltIn is true for the profiles shipped with swctl
+	BuiltIn bool `json:"builtIn"`
+	// Metadata of the profile
+	Metadata profile.Metadata `json:"metadata"`
+	// Template is the configuration template, empty if the profile uses
+	// the microservices of its base profile
+	Template string `json:"-"`
+}
+
+// profileFile is the content of a profile file
+type profileFile struct {
+	Profile       profile.Metadata `yaml:"profile"`
+	Microservices []interface{}    `yaml:"microservices"`
+}
+
+var builtInProfiles = []Profile{
+	{
+		Name:    "default",
+		BuiltIn: true,
+		Metadata: profile.Metadata{
+			Description: "All the microservices and infrastructure components",
+			Base:        profile.Default,
+		},
+	},
+	{
+		Name:    "minimal",
+		BuiltIn: true,
+		Metadata: profile.Metadata{
+			Description:           "Essential microservices and minimal infrastructure",
+			Base:                  profile.Minimal,
+			ConfigurationTemplate: "minimal",
+			Infrastructure: profile.Infrastructure{
+				Minimal: true,
+			},
+		},
+	},
+}
+
+// GetProfilesPath returns the directory of the user defined profiles.
+func GetProfilesPath() string {
+	return filepath.FromSlash(GetConfigHome() + "/profiles")
+}
+
+// LoadProfile loads the profile with the given name from the profiles
+// directory dir, or returns a built-in profile.
+func LoadProfile(dir string, name string) (*Profile, error) {
+	prof := profile.Parse(name)
+	if prof.BuiltIn() {
+		for _, builtIn := range builtInProfiles {
+			if builtIn.Metadata.Base == prof {
+				result := builtIn
+				return &result, nil
+			}
+		}
+	}
+	path := filepath.Join(dir, name+".yaml")
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		profiles, err := ListProfiles(dir)
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, p := range profiles {
+			names = append(names, p.Name)
+		}
+		return nil, errors.Errorf("unknown profile '%s', valid profiles are: %s", name, strings.Join(names, ", "))
+	}
+	return parseProfile(path, name, string(content))
+}
+
+// ListProfiles returns the built-in profiles and the user defined profiles
+// of dir. The built-in profiles are first, and the others are sorted by name.
+func ListProfiles(dir string) ([]Profile, error) {
+	var result = append([]Profile{}, builtInProfiles...)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var profiles []Profile
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".yaml" {
+			continue
+		}
+		name := strings.TrimSuffix(file.Name(), ".yaml")
+		if profile.Parse(name).BuiltIn() {
+			continue
+		}
+		path := filepath.Join(dir, file.Name())
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		p, err := parseProfile(path, name, string(content))
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, *p)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+	return append(result, profiles...), nil
+}
+
+// parseProfile reads the metadata of a profile, rendering its template
+// with empty values
+func parseProfile(path string, name string, content string) (*Profile, error) {
+	tmpl, err := parseTemplate(name, content)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing profile %s", path)
+	}
+	var rendered bytes.Buffer
+	if err = tmpl.Execute(&rendered, &PlaceHolder{}); err != nil {
+		return nil, errors.Wrapf(err, "parsing profile %s", path)
+	}
+	var file profileFile
+	if err = yaml.Unmarshal(rendered.Bytes(), &file); err != nil {
+		return nil, errors.Wrapf(err, "parsing profile %s", path)
+	}
+	result := &Profile{
+		Name:     name,
+		Metadata: file.Profile,
+	}
+	if result.Metadata.Base == "" {
+		result.Metadata.Base = profile.Default
+	}
+	result.Metadata.Base = profile.Parse(string(result.Metadata.Base))
+	if !result.Metadata.Base.BuiltIn() {
+		return nil, errors.Errorf("profile %s: base must be default or minimal, got '%s'", path, result.Metadata.Base)
+	}
+	if len(file.Microservices) > 0 {
+		result.Template = content
+	}
+	return result, nil
+}
+
+// LoadProfileConfiguration renders the configuration of a profile. A
+// profile without microservices uses the ones of its base profile. The
+// microservices are then filtered by the functional areas of the profile.
+func LoadProfileConfiguration(p *Profile, placeHolder *PlaceHolder) (*Configuration, error) {
+	var conf *Configuration
+	var err error
+	if p.Template != "" {
+		conf, err = FromTemplate(p.Template, placeHolder)
+	} else {
+		conf, err = LoadConfigurationOrDefault(placeHolder, p.Metadata.Base)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Metadata.FunctionalAreas) == 0 {
+		return conf, nil
+	}
+	var found = map[string]sitewhereiov1alpha4.SiteWhereMicroserviceSpec{}
+	for _, ms := range conf.Microservices {
+		found[ms.FunctionalArea] = ms
+	}
+	var microservices []sitewhereiov1alpha4.SiteWhereMicroserviceSpec
+	for _, area := range p.Metadata.FunctionalAreas {
+		ms, ok := found[area]
+		if !ok {
+			return nil, errors.Errorf("profile %s: unknown functional area '%s'", p.Name, area)
+		}
+		microservices = append(microservices, ms)
+	}
+	return &Configuration{Microservices: microservices}, nil
+}
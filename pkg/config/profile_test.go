@@ -0,0 +1,150 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sitewhere/swctl/pkg/install/profile"
+)
+
+const analyticsProfile = `profile:
+  description: Analytics only
+  functionalAreas:
+  - instance-management
+  - event-management
+  infrastructure:
+    without:
+    - nifi
+microservices:
+- functionalarea: instance-management
+  podspec:
+    dockerspec:
+      tag: "{{ .Tag }}"
+- functionalarea: event-management
+- functionalarea: device-management
+`
+
+func TestLoadProfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "swctl-profiles")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"analytics.yaml": analyticsProfile,
+		"edge.yaml":      "profile:\n  description: Edge gateway\n  base: Minimal\n",
+		"invalid.yaml":   "profile:\n  base: edge\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	data := []struct {
+		name     string
+		profile  string
+		builtIn  bool
+		base     profile.SiteWhereProfile
+		template bool
+		err      bool
+	}{
+		{
+			name:    "built-in",
+			profile: "Minimal",
+			builtIn: true,
+			base:    profile.Minimal,
+		},
+		{
+			name:     "user-template",
+			profile:  "analytics",
+			base:     profile.Default,
+			template: true,
+		},
+		{
+			name:    "user-base",
+			profile: "edge",
+			base:    profile.Minimal,
+		},
+		{
+			name:    "invalid-base",
+			profile: "invalid",
+			err:     true,
+		},
+		{
+			name:    "unknown",
+			profile: "dev",
+			err:     true,
+		},
+	}
+	for _, single := range data {
+		t.Run(single.name, func(single struct {
+			name     string
+			profile  string
+			builtIn  bool
+			base     profile.SiteWhereProfile
+			template bool
+			err      bool
+		}) func(t *testing.T) {
+			return func(t *testing.T) {
+				prof, err := LoadProfile(dir, single.profile)
+				if single.err {
+					if err == nil {
+						t.Fatalf("expected error loading %s", single.profile)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if prof.BuiltIn != single.builtIn || prof.Metadata.Base != single.base {
+					t.Fatalf("unexpected profile %s, built-in %t, base %s", prof.Name, prof.BuiltIn, prof.Metadata.Base)
+				}
+				if (prof.Template != "") != single.template {
+					t.Fatalf("unexpected template for profile %s", prof.Name)
+				}
+			}
+		}(single))
+	}
+}
+
+func TestLoadProfileConfiguration(t *testing.T) {
+	prof, err := parseProfile("analytics.yaml", "analytics", analyticsProfile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(prof.Metadata.Infrastructure.Without) != 1 || prof.Metadata.Infrastructure.Without[0] != "nifi" {
+		t.Fatalf("unexpected infrastructure %v", prof.Metadata.Infrastructure)
+	}
+	conf, err := LoadProfileConfiguration(prof, &PlaceHolder{Tag: "3.0.5"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(conf.Microservices) != 2 {
+		t.Fatalf("expected 2 microservices, got %d", len(conf.Microservices))
+	}
+	if conf.Microservices[0].FunctionalArea != "instance-management" || conf.Microservices[0].PodSpec.DockerSpec.Tag != "3.0.5" {
+		t.Fatalf("unexpected microservice %v", conf.Microservices[0])
+	}
+	prof.Metadata.FunctionalAreas = append(prof.Metadata.FunctionalAreas, "label-generation")
+	if _, err = LoadProfileConfiguration(prof, &PlaceHolder{}); err == nil {
+		t.Fatalf("expected error for unknown functional area")
+	}
+}
@@ -45,6 +45,16 @@ type PlaceHolder struct {
 	Repository string
 	// Docker image tag
 	Tag string
+	// Keycloak is the identity provider used by the microservices
+	Keycloak ServiceEndpoint
+	// Kafka is the external Kafka cluster, if Host is set
+	Kafka ServiceEndpoint
+	// PostgreSQL is the external PostgreSQL database, if Host is set
+	PostgreSQL ServiceEndpoint
+	// InfluxDB is the external InfluxDB database, if Host is set
+	InfluxDB ServiceEndpoint
+	// Redis is the external Redis, if Host is set
+	Redis ServiceEndpoint
 }
 
 // GetConfigPath returns the path for SiteWhere Control CLI configuration path.
@@ -0,0 +1,227 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Resources are the CPU and memory of a container
+type Resources struct {
+	// CPU quantity
+	CPU string `yaml:"cpu,omitempty" json:"cpu,omitempty"`
+	// Memory quantity
+	Memory string `yaml:"memory,omitempty" json:"memory,omitempty"`
+}
+
+// ComponentSize is the size of an infrastructure component. Empty values
+// use the defaults of the chart.
+type ComponentSize struct {
+	// Replicas of the component
+	Replicas int `yaml:"replicas,omitempty" json:"replicas,omitempty"`
+	// Requests are the resources requested by each replica
+	Requests Resources `yaml:"requests,omitempty" json:"requests,omitempty"`
+	// Limits are the resources limits of each replica
+	Limits Resources `yaml:"limits,omitempty" json:"limits,omitempty"`
+	// Storage is the size of the PVC of each replica
+	Storage string `yaml:"storage,omitempty" json:"storage,omitempty"`
+}
+
+// SizingPreset is a named size of SiteWhere Infrastructure
+type SizingPreset struct {
+	// Name of the preset
+	Name string `yaml:"name" json:"name"`
+	// Description of the preset
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Kafka brokers
+	Kafka ComponentSize `yaml:"kafka,omitempty" json:"kafka,omitempty"`
+	// Zookeeper of Kafka
+	Zookeeper ComponentSize `yaml:"zookeeper,omitempty" json:"zookeeper,omitempty"`
+	// PostgreSQL database
+	PostgreSQL ComponentSize `yaml:"postgresql,omitempty" json:"postgresql,omitempty"`
+	// InfluxDB database
+	InfluxDB ComponentSize `yaml:"influxdb,omitempty" json:"influxdb,omitempty"`
+	// Redis
+	Redis ComponentSize `yaml:"redis,omitempty" json:"redis,omitempty"`
+	// Keycloak
+	Keycloak ComponentSize `yaml:"keycloak,omitempty" json:"keycloak,omitempty"`
+	// BuiltIn is true for the presets shipped with swctl
+	BuiltIn bool `yaml:"-" json:"builtIn"`
+}
+
+var builtInSizingPresets = []SizingPreset{
+	{
+		Name:        "small",
+		Description: "Single replicas with small resources, for development and evaluation",
+		Kafka:       componentSize(1, "250m", "512Mi", "1", "1Gi", "10Gi"),
+		Zookeeper:   componentSize(1, "100m", "256Mi", "500m", "512Mi", "5Gi"),
+		PostgreSQL:  componentSize(1, "250m", "256Mi", "1", "1Gi", "8Gi"),
+		InfluxDB:    componentSize(1, "250m", "256Mi", "1", "1Gi", "10Gi"),
+		Redis:       componentSize(1, "100m", "128Mi", "500m", "256Mi", "2Gi"),
+		Keycloak:    componentSize(1, "250m", "512Mi", "1", "1Gi", "2Gi"),
+	},
+	{
+		Name:        "medium",
+		Description: "Replicated Kafka and Redis, for staging and small production",
+		Kafka:       componentSize(3, "500m", "2Gi", "2", "4Gi", "50Gi"),
+		Zookeeper:   componentSize(3, "250m", "512Mi", "1", "1Gi", "10Gi"),
+		PostgreSQL:  componentSize(1, "500m", "1Gi", "2", "2Gi", "20Gi"),
+		InfluxDB:    componentSize(1, "500m", "1Gi", "2", "4Gi", "50Gi"),
+		Redis:       componentSize(3, "250m", "256Mi", "1", "512Mi", "8Gi"),
+		Keycloak:    componentSize(1, "500m", "1Gi", "1", "2Gi", "8Gi"),
+	},
+	{
+		Name:        "large",
+		Description: "Replicated components with large resources, for production",
+		Kafka:       componentSize(3, "1", "4Gi", "4", "8Gi", "200Gi"),
+		Zookeeper:   componentSize(3, "500m", "1Gi", "2", "2Gi", "20Gi"),
+		PostgreSQL:  componentSize(2, "1", "2Gi", "4", "4Gi", "100Gi"),
+		InfluxDB:    componentSize(1, "1", "4Gi", "4", "8Gi", "200Gi"),
+		Redis:       componentSize(3, "500m", "512Mi", "2", "2Gi", "16Gi"),
+		Keycloak:    componentSize(2, "1", "1Gi", "2", "2Gi", "20Gi"),
+	},
+}
+
+func componentSize(replicas int, requestsCPU, requestsMemory, limitsCPU, limitsMemory, storage string) ComponentSize {
+	return ComponentSize{
+		Replicas: replicas,
+		Requests: Resources{CPU: requestsCPU, Memory: requestsMemory},
+		Limits:   Resources{CPU: limitsCPU, Memory: limitsMemory},
+		Storage:  storage,
+	}
+}
+
+// GetSizingPresetsPath returns the directory of the user defined sizing presets.
+func GetSizingPresetsPath() string {
+	return filepath.FromSlash(GetConfigHome() + "/sizes")
+}
+
+// LoadSizingPreset loads the sizing preset with the given name. A user preset,
+// stored as NAME.yaml in dir, takes precedence over a built-in preset.
+func LoadSizingPreset(dir string, name string) (*SizingPreset, error) {
+	path := filepath.Join(dir, name+".yaml")
+	content, err := ioutil.ReadFile(path)
+	if err == nil {
+		return parseSizingPreset(path, name, content)
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, preset := range builtInSizingPresets {
+		if preset.Name == name {
+			result := preset
+			result.BuiltIn = true
+			return &result, nil
+		}
+	}
+	presets, err := ListSizingPresets(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, preset := range presets {
+		names = append(names, preset.Name)
+	}
+	return nil, errors.Errorf("unknown size '%s', valid sizes are: %s", name, strings.Join(names, ", "))
+}
+
+// ListSizingPresets returns the built-in presets and the user presets of dir,
+// sorted by name.
+func ListSizingPresets(dir string) ([]SizingPreset, error) {
+	var found = map[string]SizingPreset{}
+	for _, preset := range builtInSizingPresets {
+		preset.BuiltIn = true
+		found[preset.Name] = preset
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".yaml" {
+			continue
+		}
+		name := strings.TrimSuffix(file.Name(), ".yaml")
+		path := filepath.Join(dir, file.Name())
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		preset, err := parseSizingPreset(path, name, content)
+		if err != nil {
+			return nil, err
+		}
+		found[name] = *preset
+	}
+	var result []SizingPreset
+	for _, preset := range found {
+		result = append(result, preset)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+func parseSizingPreset(path string, name string, content []byte) (*SizingPreset, error) {
+	var preset SizingPreset
+	if err := yaml.UnmarshalStrict(content, &preset); err != nil {
+		return nil, errors.Wrapf(err, "parsing sizing preset %s", path)
+	}
+	preset.Name = name
+	for component, size := range preset.components() {
+		if err := size.validate(); err != nil {
+			return nil, errors.Wrapf(err, "sizing preset %s, %s", path, component)
+		}
+	}
+	return &preset, nil
+}
+
+func (p *SizingPreset) components() map[string]ComponentSize {
+	return map[string]ComponentSize{
+		"kafka":      p.Kafka,
+		"zookeeper":  p.Zookeeper,
+		"postgresql": p.PostgreSQL,
+		"influxdb":   p.InfluxDB,
+		"redis":      p.Redis,
+		"keycloak":   p.Keycloak,
+	}
+}
+
+func (s *ComponentSize) validate() error {
+	if s.Replicas < 0 {
+		return errors.Errorf("invalid replicas %d", s.Replicas)
+	}
+	for _, quantity := range []string{s.Requests.CPU, s.Requests.Memory, s.Limits.CPU, s.Limits.Memory, s.Storage} {
+		if quantity == "" {
+			continue
+		}
+		if _, err := resource.ParseQuantity(quantity); err != nil {
+			return errors.Errorf("invalid quantity '%s'", quantity)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSizingPreset(t *testing.T) {
+	dir, err := ioutil.TempDir("", "swctl-sizes")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"edge.yaml":    "description: Edge gateway\nkafka:\n  replicas: 1\n  storage: 5Gi\n",
+		"small.yaml":   "redis:\n  replicas: 2\n",
+		"invalid.yaml": "kafka:\n  requests:\n    cpu: lots\n",
+		"typo.yaml":    "kafak:\n  replicas: 1\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	data := []struct {
+		name     string
+		size     string
+		builtIn  bool
+		replicas int
+		err      bool
+	}{
+		{
+			name:     "built-in",
+			size:     "medium",
+			builtIn:  true,
+			replicas: 3,
+		},
+		{
+			name:     "user",
+			size:     "edge",
+			replicas: 1,
+		},
+		{
+			name:     "user-overrides-built-in",
+			size:     "small",
+			replicas: 0,
+		},
+		{
+			name: "invalid-quantity",
+			size: "invalid",
+			err:  true,
+		},
+		{
+			name: "unknown-field",
+			size: "typo",
+			err:  true,
+		},
+		{
+			name: "unknown",
+			size: "huge",
+			err:  true,
+		},
+	}
+	for _, single := range data {
+		t.Run(single.name, func(single struct {
+			name     string
+			size     string
+			builtIn  bool
+			replicas int
+			err      bool
+		}) func(t *testing.T) {
+			return func(t *testing.T) {
+				preset, err := LoadSizingPreset(dir, single.size)
+				if single.err {
+					if err == nil {
+						t.Fatalf("expected error loading %s", single.size)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if preset.Name != single.size || preset.BuiltIn != single.builtIn {
+					t.Fatalf("unexpected preset %s, built-in %t", preset.Name, preset.BuiltIn)
+				}
+				if preset.Kafka.Replicas != single.replicas {
+					t.Fatalf("expected %d kafka replicas, got %d", single.replicas, preset.Kafka.Replicas)
+				}
+			}
+		}(single))
+	}
+}
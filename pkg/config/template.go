@@ -18,14 +18,120 @@ package config
 
 import (
 	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
 	"text/template"
 
 	"gopkg.in/yaml.v2"
 )
 
+// templateFuncs are the functions available in the configuration templates
+var templateFuncs = template.FuncMap{
+	// quote renders a value as a double quoted YAML string
+	"quote": func(value interface{}) string {
+		return strconv.Quote(fmt.Sprint(value))
+	},
+}
+
+// InfrastructureTemplateName is the name of the template that renders the
+// environment variables of the infrastructure used by a microservice
+const InfrastructureTemplateName = "sitewhere.infrastructure"
+
+// infrastructureTemplate renders the environment variables of the external
+// infrastructure and of Keycloak. The configuration templates include it in
+// the env of every microservice. The passwords are plain values, readable by
+// anyone who can read the instance and microservice resources.
+const infrastructureTemplate = `{{ define "` + InfrastructureTemplateName + `" }}
+{{- with .Kafka }}{{ if .Host }}
+    - name: sitewhere.config.kafka.hostname
+      value: {{ quote .Host }}
+      valuefrom: null
+    - name: sitewhere.config.kafka.port
+      value: {{ quote .Port }}
+      valuefrom: null
+{{- end }}{{ end }}
+{{- with .PostgreSQL }}{{ if .Host }}
+    - name: sitewhere.config.postgresql.hostname
+      value: {{ quote .Host }}
+      valuefrom: null
+    - name: sitewhere.config.postgresql.port
+      value: {{ quote .Port }}
+      valuefrom: null
+    - name: sitewhere.config.postgresql.username
+      value: {{ quote .Username }}
+      valuefrom: null
+    - name: sitewhere.config.postgresql.password
+      value: {{ quote .Password }}
+      valuefrom: null
+{{- end }}{{ end }}
+{{- with .InfluxDB }}{{ if .Host }}
+    - name: sitewhere.config.influxdb.hostname
+      value: {{ quote .Host }}
+      valuefrom: null
+    - name: sitewhere.config.influxdb.port
+      value: {{ quote .Port }}
+      valuefrom: null
+    - name: sitewhere.config.influxdb.username
+      value: {{ quote .Username }}
+      valuefrom: null
+    - name: sitewhere.config.influxdb.password
+      value: {{ quote .Password }}
+      valuefrom: null
+    - name: sitewhere.config.influxdb.database
+      value: {{ quote .Database }}
+      valuefrom: null
+{{- end }}{{ end }}
+{{- with .Redis }}{{ if .Host }}
+    - name: sitewhere.config.redis.hostname
+      value: {{ quote .Host }}
+      valuefrom: null
+    - name: sitewhere.config.redis.port
+      value: {{ quote .Port }}
+      valuefrom: null
+    - name: sitewhere.config.redis.password
+      value: {{ quote .Password }}
+      valuefrom: null
+{{- end }}{{ end }}
+    - name: sitewhere.config.keycloak.service.name
+      value: {{ quote .Keycloak.Host }}
+      valuefrom: null
+    - name: sitewhere.config.keycloak.api.port
+      value: {{ quote .Keycloak.Port }}
+      valuefrom: null
+    - name: sitewhere.config.keycloak.realm
+      value: sitewhere
+      valuefrom: null
+    - name: sitewhere.config.keycloak.master.realm
+      value: master
+      valuefrom: null
+    - name: sitewhere.config.keycloak.master.username
+      value: {{ quote .Keycloak.Username }}
+      valuefrom: null
+    - name: sitewhere.config.keycloak.master.password
+      value: {{ quote .Keycloak.Password }}
+      valuefrom: null{{ end }}`
+
+// parseTemplate parses a configuration template, with the templates it
+// can include
+func parseTemplate(name string, templateContent string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(infrastructureTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return tmpl.Parse(templateContent)
+}
+
+// UsesInfrastructureTemplate returns true if the configuration template
+// renders the infrastructure of the microservices. Templates saved before
+// the infrastructure was configurable do not.
+func UsesInfrastructureTemplate(templateContent string) bool {
+	return strings.Contains(templateContent, `template "`+InfrastructureTemplateName+`"`)
+}
+
 // FromTemplate renders the configuration from a template
 func FromTemplate(templateContent string, placeHolder *PlaceHolder) (*Configuration, error) {
-	tmpl, err := template.New(placeHolder.InstanceName).Parse(templateContent)
+	tmpl, err := parseTemplate(placeHolder.InstanceName, templateContent)
 	if err != nil {
 		return nil, err
 	}
@@ -101,3 +101,110 @@ func TestFromTemplate(t *testing.T) {
 		}(single))
 	}
 }
+
+func TestFromTemplateExternalInfrastructure(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		name        string
+		placeHolder *PlaceHolder
+		expected    map[string]string
+		missing     []string
+	}{
+		{
+			name: "default-infrastructure",
+			placeHolder: &PlaceHolder{
+				InstanceName: "sitewhere",
+				Keycloak:     DefaultKeycloak,
+			},
+			expected: map[string]string{
+				"sitewhere.config.keycloak.service.name": "sitewhere-keycloak-http",
+				"sitewhere.config.keycloak.api.port":     "80",
+			},
+			missing: []string{"sitewhere.config.kafka.hostname", "sitewhere.config.postgresql.hostname"},
+		},
+		{
+			name: "external-infrastructure",
+			placeHolder: &PlaceHolder{
+				InstanceName: "sitewhere",
+				Keycloak:     ServiceEndpoint{Host: "keycloak.example.com", Port: 8443, Username: "admin", Password: "s3cr\"t"},
+				Kafka:        ServiceEndpoint{Host: "kafka.example.com", Port: 9092},
+				PostgreSQL:   ServiceEndpoint{Host: "pg.example.com", Port: 5432, Username: "sw", Password: "pw"},
+			},
+			expected: map[string]string{
+				"sitewhere.config.keycloak.service.name":    "keycloak.example.com",
+				"sitewhere.config.keycloak.api.port":        "8443",
+				"sitewhere.config.keycloak.master.password": "s3cr\"t",
+				"sitewhere.config.kafka.hostname":           "kafka.example.com",
+				"sitewhere.config.kafka.port":               "9092",
+				"sitewhere.config.postgresql.username":      "sw",
+			},
+			missing: []string{"sitewhere.config.redis.hostname"},
+		},
+	}
+	for _, single := range data {
+		t.Run(single.name, func(single struct {
+			name        string
+			placeHolder *PlaceHolder
+			expected    map[string]string
+			missing     []string
+		}) func(t *testing.T) {
+			return func(t *testing.T) {
+				for _, templateContent := range []string{defaultTemplate, minimalTemplate} {
+					result, err := FromTemplate(templateContent, single.placeHolder)
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					for _, ms := range result.Microservices {
+						env := map[string]string{}
+						for _, e := range ms.PodSpec.Env {
+							env[e.Name] = e.Value
+						}
+						for name, value := range single.expected {
+							if env[name] != value {
+								t.Fatalf("%s: expected %s=%q got %q", ms.FunctionalArea, name, value, env[name])
+							}
+						}
+						for _, name := range single.missing {
+							if _, ok := env[name]; ok {
+								t.Fatalf("%s: unexpected env %s", ms.FunctionalArea, name)
+							}
+						}
+					}
+				}
+			}
+		}(single))
+	}
+}
+
+func TestUsesInfrastructureTemplate(t *testing.T) {
+	t.Parallel()
+	for _, templateContent := range []string{defaultTemplate, minimalTemplate} {
+		if !UsesInfrastructureTemplate(templateContent) {
+			t.Fatalf("expected built-in template to render the infrastructure")
+		}
+	}
+	saved := "microservices:\n- functionalarea: asset-management\n"
+	if UsesInfrastructureTemplate(saved) {
+		t.Fatalf("expected saved template without infrastructure to be outdated")
+	}
+}
+
+func TestExternalInfrastructureDefaultPorts(t *testing.T) {
+	t.Parallel()
+	infra := ExternalInfrastructure{
+		Kafka:      ServiceEndpoint{Host: "kafka.example.com"},
+		PostgreSQL: ServiceEndpoint{Host: "pg.example.com", Port: 15432},
+	}
+	if port := infra.KafkaEndpoint().Port; port != DefaultKafkaPort {
+		t.Fatalf("expected kafka port %d, got %d", DefaultKafkaPort, port)
+	}
+	if port := infra.PostgreSQLEndpoint().Port; port != 15432 {
+		t.Fatalf("expected postgresql port 15432, got %d", port)
+	}
+	if port := infra.RedisEndpoint().Port; port != 0 {
+		t.Fatalf("expected no port for the redis installed by swctl, got %d", port)
+	}
+	if !infra.Configured() || (&ExternalInfrastructure{}).Configured() {
+		t.Fatalf("expected only the infrastructure with hosts to be configured")
+	}
+}
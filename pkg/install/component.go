@@ -0,0 +1,148 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package install
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Component is an infrastructure component of SiteWhere Infrastructure chart.
+// Its value is the key of the component values in the chart.
+type Component string
+
+const (
+	// PostgreSQL relational database
+	PostgreSQL Component = "postgresql"
+	// InfluxDB time series database
+	InfluxDB Component = "influxdb"
+	// Redis key-value store
+	Redis Component = "redis"
+	// Nifi data flow
+	Nifi Component = "nifi"
+	// Mosquitto MQTT broker
+	Mosquitto Component = "mosquitto"
+	// Strimzi Kafka cluster
+	Strimzi Component = "strimzi"
+	// Keycloak identity provider
+	Keycloak Component = "keycloak"
+)
+
+// ComponentInfo describes an infrastructure component
+type ComponentInfo struct {
+	// Component is the component
+	Component Component
+	// Description of the component
+	Description string
+	// Aliases are alternative names accepted for the component
+	Aliases []string
+	// Workloads are the name fragments of the workloads of the component
+	Workloads []string
+}
+
+// registry are the infrastructure components, in install order
+var registry = []ComponentInfo{
+	{Component: PostgreSQL, Description: "PostgreSQL database", Aliases: []string{"postgres"}, Workloads: []string{"postgresql"}},
+	{Component: InfluxDB, Description: "InfluxDB time series database", Workloads: []string{"influxdb"}},
+	{Component: Redis, Description: "Redis key-value store", Workloads: []string{"redis"}},
+	{Component: Nifi, Description: "Apache NiFi", Workloads: []string{"nifi"}},
+	{Component: Mosquitto, Description: "Mosquitto MQTT broker", Aliases: []string{"mqtt"}, Workloads: []string{"mosquitto"}},
+	{Component: Strimzi, Description: "Strimzi Kafka cluster", Aliases: []string{"kafka"}, Workloads: []string{"strimzi", "kafka"}},
+	{Component: Keycloak, Description: "Keycloak identity provider", Workloads: []string{"keycloak"}},
+}
+
+// Components returns the infrastructure components, in install order
+func Components() []ComponentInfo {
+	return append([]ComponentInfo{}, registry...)
+}
+
+// ComponentNames returns the names of the infrastructure components
+func ComponentNames() []string {
+	var names []string
+	for _, info := range registry {
+		names = append(names, string(info.Component))
+	}
+	return names
+}
+
+// ParseComponent returns the component with the given name or alias
+func ParseComponent(name string) (Component, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, info := range registry {
+		if string(info.Component) == name {
+			return info.Component, nil
+		}
+		for _, alias := range info.Aliases {
+			if alias == name {
+				return info.Component, nil
+			}
+		}
+	}
+	return "", errors.Errorf("unknown infrastructure component %q, valid components are: %s", name, strings.Join(ComponentNames(), ", "))
+}
+
+// SelectComponents returns the components to install. If with is empty, all
+// components are selected. The components in without are then removed.
+func SelectComponents(with []string, without []string) (map[Component]bool, error) {
+	selected := map[Component]bool{}
+	if len(with) == 0 {
+		for _, info := range registry {
+			selected[info.Component] = true
+		}
+	}
+	for _, name := range with {
+		component, err := ParseComponent(name)
+		if err != nil {
+			return nil, err
+		}
+		selected[component] = true
+	}
+	for _, name := range without {
+		component, err := ParseComponent(name)
+		if err != nil {
+			return nil, err
+		}
+		delete(selected, component)
+	}
+	return selected, nil
+}
+
+// MatchComponent returns the component a workload belongs to. When the name
+// of the workload contains the fragments of several components, the
+// component whose fragment appears first wins, so that for example
+// sitewhere-keycloak-postgresql belongs to keycloak.
+func MatchComponent(workload string) (Component, bool) {
+	type match struct {
+		component Component
+		index     int
+	}
+	var matches []match
+	for _, info := range registry {
+		for _, fragment := range info.Workloads {
+			if index := strings.Index(workload, fragment); index >= 0 {
+				matches = append(matches, match{component: info.Component, index: index})
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return "", false
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].index < matches[j].index })
+	return matches[0].component, true
+}
@@ -0,0 +1,117 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package install
+
+import (
+	"testing"
+)
+
+func TestSelectComponents(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		name     string
+		with     []string
+		without  []string
+		expected []Component
+		err      bool
+	}{
+		{
+			name:     "all",
+			expected: []Component{PostgreSQL, InfluxDB, Redis, Nifi, Mosquitto, Strimzi, Keycloak},
+		},
+		{
+			name:     "without-managed",
+			without:  []string{"postgresql", "kafka"},
+			expected: []Component{InfluxDB, Redis, Nifi, Mosquitto, Keycloak},
+		},
+		{
+			name:     "with",
+			with:     []string{"keycloak", "redis", "mosquitto"},
+			expected: []Component{Redis, Mosquitto, Keycloak},
+		},
+		{
+			name:     "with-and-without",
+			with:     []string{"keycloak", "redis"},
+			without:  []string{"redis"},
+			expected: []Component{Keycloak},
+		},
+		{
+			name: "unknown",
+			with: []string{"mongodb"},
+			err:  true,
+		},
+	}
+	for _, single := range data {
+		t.Run(single.name, func(single struct {
+			name     string
+			with     []string
+			without  []string
+			expected []Component
+			err      bool
+		}) func(t *testing.T) {
+			return func(t *testing.T) {
+				selected, err := SelectComponents(single.with, single.without)
+				if single.err {
+					if err == nil {
+						t.Fatal("expected error")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(selected) != len(single.expected) {
+					t.Fatalf("expected %v, got %v", single.expected, selected)
+				}
+				for _, component := range single.expected {
+					if !selected[component] {
+						t.Fatalf("expected %s to be selected, got %v", component, selected)
+					}
+				}
+			}
+		}(single))
+	}
+}
+
+func TestMatchComponent(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		workload string
+		expected Component
+		found    bool
+	}{
+		{workload: "sitewhere-postgresql", expected: PostgreSQL, found: true},
+		{workload: "sitewhere-keycloak-postgresql", expected: Keycloak, found: true},
+		{workload: "sitewhere-kafka-zookeeper", expected: Strimzi, found: true},
+		{workload: "strimzi-cluster-operator", expected: Strimzi, found: true},
+		{workload: "sitewhere-operator", found: false},
+	}
+	for _, single := range data {
+		t.Run(single.workload, func(single struct {
+			workload string
+			expected Component
+			found    bool
+		}) func(t *testing.T) {
+			return func(t *testing.T) {
+				component, found := MatchComponent(single.workload)
+				if found != single.found || component != single.expected {
+					t.Fatalf("expected %s (%v), got %s (%v)", single.expected, single.found, component, found)
+				}
+			}
+		}(single))
+	}
+}
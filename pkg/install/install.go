@@ -16,6 +16,10 @@
 
 package install
 
+import (
+	"github.com/sitewhere/swctl/pkg/status"
+)
+
 // Status Status of a installable item.
 type Status string
 
@@ -34,4 +38,28 @@ type SiteWhereInstall struct {
 	Release string `json:"release,omitempty"`
 	// Namespace
 	Namespace string `json:"namespace,omitempty"`
+	// Components are the status of each installed component
+	Components []status.SiteWhereStatus `json:"components,omitempty"`
+	// Infrastructure are the status of each infrastructure component
+	Infrastructure []status.SiteWhereStatus `json:"infrastructure,omitempty"`
+	// RolledBack are the resources removed after a failed atomic install
+	RolledBack []status.SiteWhereStatus `json:"rolledBack,omitempty"`
+	// Errors are the errors of the resources that could not be deleted
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Complete returns true if every component is installed and ready,
+// and every infrastructure component is ready or skipped.
+func (i *SiteWhereInstall) Complete() bool {
+	for _, component := range i.Components {
+		if component.Status != status.Installed {
+			return false
+		}
+	}
+	for _, component := range i.Infrastructure {
+		if component.Status != status.Installed && component.Status != status.Skipped {
+			return false
+		}
+	}
+	return true
 }
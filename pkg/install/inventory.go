@@ -0,0 +1,38 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package install
+
+// InventoryItem is a resource owned by SiteWhere
+type InventoryItem struct {
+	// Kind of the resource
+	Kind string `json:"kind"`
+	// Name of the resource
+	Name string `json:"name"`
+	// Namespace of the resource, empty for cluster-scoped resources
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// SiteWhereInventory are the resources owned by a SiteWhere installation,
+// in deletion order.
+type SiteWhereInventory struct {
+	// Release is the name of the SiteWhere Infrastructure release
+	Release string `json:"release"`
+	// Namespace is the SiteWhere System Namespace
+	Namespace string `json:"namespace"`
+	// Items are the resources owned by SiteWhere
+	Items []InventoryItem `json:"items"`
+}
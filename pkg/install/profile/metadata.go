@@ -0,0 +1,70 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package profile
+
+import (
+	"strings"
+)
+
+// Metadata describe a profile: the microservices of the instances and the
+// infrastructure toggles of the install.
+type Metadata struct {
+	// Description of the profile
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Base is the profile whose microservices are used when the profile
+	// does not define them, Default if empty
+	Base SiteWhereProfile `yaml:"base,omitempty" json:"base,omitempty"`
+	// FunctionalAreas are the functional areas of the microservices to
+	// deploy, all if empty
+	FunctionalAreas []string `yaml:"functionalAreas,omitempty" json:"functionalAreas,omitempty"`
+	// ConfigurationTemplate is the configuration template of the instances
+	ConfigurationTemplate string `yaml:"configurationTemplate,omitempty" json:"configurationTemplate,omitempty"`
+	// DatasetTemplate is the dataset template of the instances
+	DatasetTemplate string `yaml:"datasetTemplate,omitempty" json:"datasetTemplate,omitempty"`
+	// Infrastructure are the infrastructure toggles used by install
+	Infrastructure Infrastructure `yaml:"infrastructure,omitempty" json:"infrastructure,omitempty"`
+}
+
+// Infrastructure are the infrastructure toggles of a profile
+type Infrastructure struct {
+	// Minimal if true, deploy minimal infrastucure
+	Minimal bool `yaml:"minimal,omitempty" json:"minimal,omitempty"`
+	// With are the infrastructure components to install, all if empty
+	With []string `yaml:"with,omitempty" json:"with,omitempty"`
+	// Without are the infrastructure components not to install
+	Without []string `yaml:"without,omitempty" json:"without,omitempty"`
+	// Size is the name of the sizing preset of the infrastructure
+	Size string `yaml:"size,omitempty" json:"size,omitempty"`
+}
+
+// Parse returns the profile with the given name. The names of the built-in
+// profiles are case insensitive.
+func Parse(name string) SiteWhereProfile {
+	switch strings.ToLower(name) {
+	case strings.ToLower(string(Default)):
+		return Default
+	case strings.ToLower(string(Minimal)):
+		return Minimal
+	default:
+		return SiteWhereProfile(name)
+	}
+}
+
+// BuiltIn returns true for the profiles shipped with swctl
+func (p SiteWhereProfile) BuiltIn() bool {
+	return p == Default || p == Minimal
+}
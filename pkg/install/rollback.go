@@ -0,0 +1,47 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package install
+
+import (
+	"github.com/sitewhere/swctl/pkg/status"
+)
+
+// SiteWhereRollback destribe the rollback of the SiteWhere Infrastructure release.
+type SiteWhereRollback struct {
+	// Release
+	Release string `json:"release,omitempty"`
+	// Namespace
+	Namespace string `json:"namespace,omitempty"`
+	// PreviousRevision is the revision of the release before the rollback
+	PreviousRevision int `json:"previous_revision,omitempty"`
+	// TargetRevision is the revision rolled back to
+	TargetRevision int `json:"target_revision,omitempty"`
+	// Revision of the release after the rollback
+	Revision int `json:"revision,omitempty"`
+	// Components are the status of each component after the rollback
+	Components []status.SiteWhereStatus `json:"components,omitempty"`
+}
+
+// Complete returns true if every component is installed and ready after the rollback.
+func (r *SiteWhereRollback) Complete() bool {
+	for _, component := range r.Components {
+		if component.Status != status.Installed {
+			return false
+		}
+	}
+	return true
+}
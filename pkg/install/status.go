@@ -0,0 +1,75 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package install
+
+import (
+	"time"
+)
+
+// ReleaseRevision describe a revision of the SiteWhere Infrastructure release.
+type ReleaseRevision struct {
+	// Revision number
+	Revision int `json:"revision"`
+	// Updated is the time of the deployment of the revision
+	Updated time.Time `json:"updated"`
+	// Status of the revision
+	Status string `json:"status"`
+	// Chart is the name and version of the chart
+	Chart string `json:"chart"`
+	// AppVersion is the version of the application of the chart
+	AppVersion string `json:"app_version"`
+	// Description of the revision
+	Description string `json:"description"`
+}
+
+// CRDVersion describe a version of a SiteWhere Custom Resource Definition.
+type CRDVersion struct {
+	// Name of the Custom Resource Definition
+	Name string `json:"name"`
+	// Version name
+	Version string `json:"version"`
+	// Served indicates if the version is served by the API
+	Served bool `json:"served"`
+	// Storage indicates if the version is used to store the resources
+	Storage bool `json:"storage"`
+}
+
+// SiteWhereReleaseStatus destribe the installed SiteWhere release.
+type SiteWhereReleaseStatus struct {
+	// Release
+	Release string `json:"release,omitempty"`
+	// Namespace
+	Namespace string `json:"namespace,omitempty"`
+	// Status of the last revision
+	Status string `json:"status,omitempty"`
+	// Revision is the last revision of the release
+	Revision int `json:"revision,omitempty"`
+	// ChartVersion is the version of the deployed chart
+	ChartVersion string `json:"chart_version,omitempty"`
+	// AppVersion is the version of the application of the deployed chart
+	AppVersion string `json:"app_version,omitempty"`
+	// LastDeployed is the time of the last deployment
+	LastDeployed time.Time `json:"last_deployed,omitempty"`
+	// Notes of the last deployment
+	Notes string `json:"notes,omitempty"`
+	// OperatorImage is the image of the SiteWhere Operator
+	OperatorImage string `json:"operator_image,omitempty"`
+	// CRDs are the versions of the SiteWhere Custom Resource Definitions
+	CRDs []CRDVersion `json:"crds,omitempty"`
+	// History are the revisions of the release, the newest first
+	History []ReleaseRevision `json:"history,omitempty"`
+}
@@ -0,0 +1,35 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package install
+
+// SiteWhereTemplate destribe the rendered manifests of a SiteWhere installation.
+type SiteWhereTemplate struct {
+	// Release
+	Release string `json:"release,omitempty"`
+	// Namespace
+	Namespace string `json:"namespace,omitempty"`
+	// ChartVersion is the version of the rendered chart
+	ChartVersion string `json:"chart_version,omitempty"`
+	// Values are the merged values used to render the chart
+	Values map[string]interface{} `json:"values,omitempty"`
+	// CRDs are the Custom Resource Definitions of the chart
+	CRDs string `json:"crds,omitempty"`
+	// Manifest is the rendered Kubernetes manifest
+	Manifest string `json:"manifest,omitempty"`
+	// OutputDir is the directory where the manifests were written
+	OutputDir string `json:"output_dir,omitempty"`
+}
@@ -0,0 +1,45 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package install
+
+// ValueChange describe the change of a single value of the release.
+type ValueChange struct {
+	// Key is the path of the value
+	Key string `json:"key"`
+	// Previous value
+	Previous interface{} `json:"previous,omitempty"`
+	// Value is the new value
+	Value interface{} `json:"value,omitempty"`
+}
+
+// SiteWhereUpgrade destribe the upgrade of SiteWhere.
+type SiteWhereUpgrade struct {
+	// Release
+	Release string `json:"release,omitempty"`
+	// Namespace
+	Namespace string `json:"namespace,omitempty"`
+	// Revision of the release after the upgrade
+	Revision int `json:"revision,omitempty"`
+	// PreviousChartVersion is the version of the chart before the upgrade
+	PreviousChartVersion string `json:"previous_chart_version,omitempty"`
+	// ChartVersion is the version of the chart after the upgrade
+	ChartVersion string `json:"chart_version,omitempty"`
+	// ValueChanges are the values changed by the upgrade
+	ValueChanges []ValueChange `json:"value_changes,omitempty"`
+	// DryRun indicates that the upgrade was not applied
+	DryRun bool `json:"dry_run,omitempty"`
+}
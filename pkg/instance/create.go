@@ -20,16 +20,24 @@ package instance
 type CreateSiteWhereInstance struct {
 	// Name of the instance
 	InstanceName string `json:"instanceName"`
+	// Namespace of the instance
+	Namespace string `json:"namespace"`
 	// Docker Image Tag
 	Tag string `json:"tag"`
 	// Number of replicas
 	Replicas int32
 	// Use debug mode
 	Debug bool
+	// Functional areas of the microservices in debug mode
+	DebugMicroservices []string `json:"debugMicroservices,omitempty"`
 	// Configuration Template
 	ConfigurationTemplate string
 	// Dataset template
 	DatasetTemplate string
+	// Profile used to create the instance
+	Profile string `json:"profile,omitempty"`
 	// Instance Custom Resources Name
 	InstanceCustomResourceName string `json:"instanceCustomResourceName"`
+	// Bootstrapped is true if the instance was waited for and bootstrapped
+	Bootstrapped bool `json:"bootstrapped,omitempty"`
 }
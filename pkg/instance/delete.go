@@ -22,4 +22,7 @@ type DeleteSiteWhereInstance struct {
 	InstanceName string `json:"instanceName"`
 	// Namespace to use
 	Namespace string `json:"namespace"`
+	// PurgedNamespace is the namespace controlled by the instance deleted
+	// with it, empty if none was deleted
+	PurgedNamespace string `json:"purgedNamespace,omitempty"`
 }
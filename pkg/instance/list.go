@@ -17,6 +17,8 @@
 package instance
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	sitewhereiov1alpha4 "github.com/sitewhere/sitewhere-k8s-operator/apis/sitewhere.io/v1alpha4"
 )
 
@@ -24,6 +26,62 @@ import (
 type ListSiteWhereInstance struct {
 	// Instances found
 	Instances []sitewhereiov1alpha4.SiteWhereInstance
+	// Namespaces are the namespaces of the instances, by instance name
+	Namespaces map[string]string
 	// Microservices are the microservices of a instance
 	Microservices []sitewhereiov1alpha4.SiteWhereMicroservice
+	// Health is the live health of the microservices of a instance
+	Health []MicroserviceHealth
+	// Summaries are the health summaries of the instances, by instance name
+	Summaries map[string]InstanceSummary
+}
+
+// MicroserviceHealth describe the live health of a SiteWhere Microservice,
+// joined from its Deployment and Pods.
+type MicroserviceHealth struct {
+	// Name of the microservice
+	Name string `json:"name"`
+	// Namespace of the microservice
+	Namespace string `json:"namespace"`
+	// Deployment of the microservice
+	Deployment string `json:"deployment,omitempty"`
+	// Desired number of replicas
+	Desired int32 `json:"desired"`
+	// Updated number of replicas
+	Updated int32 `json:"updated"`
+	// Ready number of replicas
+	Ready int32 `json:"ready"`
+	// Available number of replicas
+	Available int32 `json:"available"`
+	// Tags of the images running in the pods
+	Tags []string `json:"tags,omitempty"`
+	// Images running in the pods
+	Images []string `json:"images,omitempty"`
+	// Restarts of the containers of the pods
+	Restarts int32 `json:"restarts"`
+	// Nodes running the pods
+	Nodes []string `json:"nodes,omitempty"`
+	// Created is the creation time of the Deployment
+	Created *metav1.Time `json:"created,omitempty"`
+	// LastTerminationReason is the reason of the last termination or the
+	// waiting reason of a container, for example OOMKilled or CrashLoopBackOff
+	LastTerminationReason string `json:"lastTerminationReason,omitempty"`
+}
+
+// InstanceSummary describe the health of the microservices of a SiteWhere Instance.
+type InstanceSummary struct {
+	// Microservices of the instance
+	Microservices int `json:"microservices"`
+	// ReadyMicroservices have all their desired replicas available
+	ReadyMicroservices int `json:"readyMicroservices"`
+	// Desired number of replicas of all the microservices
+	Desired int32 `json:"desired"`
+	// Available number of replicas of all the microservices
+	Available int32 `json:"available"`
+	// Restarts of the containers of all the microservices
+	Restarts int32 `json:"restarts"`
+	// Tags of the images running in the pods
+	Tags []string `json:"tags,omitempty"`
+	// Error is the reason the health is unknown
+	Error string `json:"error,omitempty"`
 }
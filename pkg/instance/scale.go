@@ -0,0 +1,37 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instance
+
+// ScaledMicroservice describe the replicas of a scaled SiteWhere Microservice.
+type ScaledMicroservice struct {
+	// Name of the microservice
+	Name string `json:"name"`
+	// Before is the number of replicas before scaling
+	Before int32 `json:"before"`
+	// After is the number of replicas after scaling
+	After int32 `json:"after"`
+}
+
+// ScaleSiteWhereInstance destribe the scaling of the microservices of a SiteWhere Instance.
+type ScaleSiteWhereInstance struct {
+	// Name of the instance
+	InstanceName string `json:"instanceName"`
+	// Namespace of the instance
+	Namespace string `json:"namespace"`
+	// Microservices scaled
+	Microservices []ScaledMicroservice `json:"microservices"`
+}
@@ -0,0 +1,49 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instance
+
+import (
+	"github.com/sitewhere/swctl/pkg/status"
+)
+
+// SpecChange describe the change of a field of a SiteWhere Custom Resource.
+type SpecChange struct {
+	// Kind of the Custom Resource
+	Kind string `json:"kind"`
+	// Name of the Custom Resource
+	Name string `json:"name"`
+	// Field is the path of the field in the spec
+	Field string `json:"field"`
+	// From is the live value
+	From string `json:"from,omitempty"`
+	// To is the updated value
+	To string `json:"to,omitempty"`
+}
+
+// UpdateSiteWhereInstance destribe the update of a SiteWhere Instance.
+type UpdateSiteWhereInstance struct {
+	// Name of the instance
+	InstanceName string `json:"instanceName"`
+	// Namespace of the instance
+	Namespace string `json:"namespace"`
+	// DryRun is true if the changes were not applied
+	DryRun bool `json:"dryRun"`
+	// Changes of the instance and microservices specs
+	Changes []SpecChange `json:"changes"`
+	// Steps are the status of the microservices of a rolling update
+	Steps []status.SiteWhereStatus `json:"steps,omitempty"`
+}
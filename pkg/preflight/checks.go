@@ -0,0 +1,232 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+
+	"github.com/sitewhere/swctl/pkg/install/profile"
+)
+
+const (
+	// MinKubernetesVersion is the minimum Kubernetes version supported
+	MinKubernetesVersion = "1.16.0"
+	// MaxKubernetesVersion is the maximum Kubernetes version tested
+	MaxKubernetesVersion = "1.20.99"
+
+	istioNamespace  = "istio-system"
+	istioDeployment = "istiod"
+
+	defaultStorageClassAnnotation     = "storageclass.kubernetes.io/is-default-class"
+	betaDefaultStorageClassAnnotation = "storageclass.beta.kubernetes.io/is-default-class"
+)
+
+// profileRequirements are the allocatable resources required by each profile
+var profileRequirements = map[profile.SiteWhereProfile]struct {
+	CPU    string
+	Memory string
+}{
+	profile.Default: {CPU: "6", Memory: "12Gi"},
+	profile.Minimal: {CPU: "4", Memory: "8Gi"},
+}
+
+// KubernetesVersionCheck checks the Kubernetes server version is in the supported range
+type KubernetesVersionCheck struct {
+	// Min is the minimum version supported
+	Min string
+	// Max is the maximum version tested
+	Max string
+}
+
+// Name of the check
+func (k *KubernetesVersionCheck) Name() string {
+	return "Kubernetes Version"
+}
+
+// Run executes the check
+func (k *KubernetesVersionCheck) Run(ctx context.Context, c *Context) CheckResult {
+	info, err := c.Clientset.Discovery().ServerVersion()
+	if err != nil {
+		return CheckResult{Result: Fail, Message: err.Error()}
+	}
+	serverVersion, err := utilversion.ParseGeneric(info.GitVersion)
+	if err != nil {
+		return CheckResult{Result: Fail, Message: err.Error()}
+	}
+	if serverVersion.LessThan(utilversion.MustParseGeneric(k.Min)) {
+		return CheckResult{Result: Fail, Message: fmt.Sprintf("server version %s is older than the minimum supported %s", info.GitVersion, k.Min)}
+	}
+	if k.Max != "" && utilversion.MustParseGeneric(k.Max).LessThan(serverVersion) {
+		return CheckResult{Result: Warn, Message: fmt.Sprintf("server version %s is newer than the tested %s", info.GitVersion, k.Max)}
+	}
+	return CheckResult{Result: Pass, Message: fmt.Sprintf("server version %s", info.GitVersion)}
+}
+
+// StorageClassCheck checks the requested StorageClass exists, or warns
+// when none was requested and there is no default StorageClass
+type StorageClassCheck struct{}
+
+// Name of the check
+func (s *StorageClassCheck) Name() string {
+	return "Storage Class"
+}
+
+// Run executes the check
+func (s *StorageClassCheck) Run(ctx context.Context, c *Context) CheckResult {
+	if c.StorageClass != "" {
+		_, err := c.Clientset.StorageV1().StorageClasses().Get(ctx, c.StorageClass, metav1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return CheckResult{Result: Fail, Message: fmt.Sprintf("storage class %s not found", c.StorageClass)}
+			}
+			return CheckResult{Result: Fail, Message: err.Error()}
+		}
+		return CheckResult{Result: Pass, Message: fmt.Sprintf("storage class %s found", c.StorageClass)}
+	}
+	classes, err := c.Clientset.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return CheckResult{Result: Fail, Message: err.Error()}
+	}
+	for _, class := range classes.Items {
+		if class.Annotations[defaultStorageClassAnnotation] == "true" || class.Annotations[betaDefaultStorageClassAnnotation] == "true" {
+			return CheckResult{Result: Pass, Message: fmt.Sprintf("default storage class %s found", class.GetName())}
+		}
+	}
+	return CheckResult{Result: Warn, Message: "no default storage class found, persistent volume claims may stay pending, use --storage-class"}
+}
+
+// NodeResourcesCheck checks the nodes have enough allocatable CPU and
+// memory for the profile
+type NodeResourcesCheck struct{}
+
+// Name of the check
+func (n *NodeResourcesCheck) Name() string {
+	return "Node Resources"
+}
+
+// Run executes the check
+func (n *NodeResourcesCheck) Run(ctx context.Context, c *Context) CheckResult {
+	requirements, ok := profileRequirements[c.Profile]
+	if !ok {
+		requirements = profileRequirements[profile.Default]
+	}
+	nodes, err := c.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return CheckResult{Result: Fail, Message: err.Error()}
+	}
+	cpu := resource.NewQuantity(0, resource.DecimalSI)
+	memory := resource.NewQuantity(0, resource.BinarySI)
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		cpu.Add(*node.Status.Allocatable.Cpu())
+		memory.Add(*node.Status.Allocatable.Memory())
+	}
+	message := fmt.Sprintf("allocatable cpu %s, memory %s (required cpu %s, memory %s)",
+		cpu.String(), memory.String(), requirements.CPU, requirements.Memory)
+	if cpu.Cmp(resource.MustParse(requirements.CPU)) < 0 || memory.Cmp(resource.MustParse(requirements.Memory)) < 0 {
+		return CheckResult{Result: Fail, Message: message}
+	}
+	return CheckResult{Result: Pass, Message: message}
+}
+
+// ClusterPermissionsCheck checks the user can create the cluster scoped
+// resources needed by the installation
+type ClusterPermissionsCheck struct{}
+
+// Name of the check
+func (p *ClusterPermissionsCheck) Name() string {
+	return "Cluster Permissions"
+}
+
+// Run executes the check
+func (p *ClusterPermissionsCheck) Run(ctx context.Context, c *Context) CheckResult {
+	attributes := []authorizationv1.ResourceAttributes{
+		{Verb: "create", Group: "", Resource: "namespaces"},
+		{Verb: "create", Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"},
+		{Verb: "create", Group: "rbac.authorization.k8s.io", Resource: "clusterroles"},
+		{Verb: "create", Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"},
+	}
+	var denied []string
+	for i := range attributes {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &attributes[i],
+			},
+		}
+		result, err := c.Clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return CheckResult{Result: Fail, Message: err.Error()}
+		}
+		if !result.Status.Allowed {
+			denied = append(denied, attributes[i].Resource)
+		}
+	}
+	if len(denied) > 0 {
+		return CheckResult{Result: Fail, Message: fmt.Sprintf("not allowed to create %s", strings.Join(denied, ", "))}
+	}
+	return CheckResult{Result: Pass, Message: "allowed to create cluster scoped resources"}
+}
+
+// IstioCheck checks the Istio control plane is running
+type IstioCheck struct{}
+
+// Name of the check
+func (i *IstioCheck) Name() string {
+	return "Istio"
+}
+
+// Run executes the check
+func (i *IstioCheck) Run(ctx context.Context, c *Context) CheckResult {
+	_, err := c.Clientset.CoreV1().Namespaces().Get(ctx, istioNamespace, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return CheckResult{Result: Fail, Message: "Istio is not installed, install istio with `istioctl install` and try again"}
+		}
+		return CheckResult{Result: Fail, Message: err.Error()}
+	}
+	deploy, err := c.Clientset.AppsV1().Deployments(istioNamespace).Get(ctx, istioDeployment, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return CheckResult{Result: Fail, Message: fmt.Sprintf("Istio control plane %s not found in %s", istioDeployment, istioNamespace)}
+		}
+		return CheckResult{Result: Fail, Message: err.Error()}
+	}
+	if !deploymentAvailable(deploy) {
+		return CheckResult{Result: Fail, Message: fmt.Sprintf("Istio control plane %s is not available", istioDeployment)}
+	}
+	return CheckResult{Result: Pass, Message: "Istio control plane is running"}
+}
+
+func deploymentAvailable(deploy *appsv1.Deployment) bool {
+	for _, cond := range deploy.Status.Conditions {
+		if cond.Type == appsv1.DeploymentAvailable {
+			return cond.Status == "True"
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package preflight
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sitewhere/swctl/pkg/install/profile"
+)
+
+func withServerVersion(clientset *fake.Clientset, gitVersion string) *fake.Clientset {
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: gitVersion}
+	return clientset
+}
+
+func TestChecks(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		name     string
+		check    Check
+		context  *Context
+		expected Result
+	}{
+		{
+			name:     "version-supported",
+			check:    &KubernetesVersionCheck{Min: MinKubernetesVersion, Max: MaxKubernetesVersion},
+			context:  &Context{Clientset: withServerVersion(fake.NewSimpleClientset(), "v1.19.3")},
+			expected: Pass,
+		},
+		{
+			name:     "version-too-old",
+			check:    &KubernetesVersionCheck{Min: MinKubernetesVersion, Max: MaxKubernetesVersion},
+			context:  &Context{Clientset: withServerVersion(fake.NewSimpleClientset(), "v1.15.0")},
+			expected: Fail,
+		},
+		{
+			name:     "version-too-new",
+			check:    &KubernetesVersionCheck{Min: MinKubernetesVersion, Max: MaxKubernetesVersion},
+			context:  &Context{Clientset: withServerVersion(fake.NewSimpleClientset(), "v1.22.1-gke.1")},
+			expected: Warn,
+		},
+		{
+			name:     "storage-class-missing",
+			check:    &StorageClassCheck{},
+			context:  &Context{Clientset: fake.NewSimpleClientset(), StorageClass: "fast"},
+			expected: Fail,
+		},
+		{
+			name:  "storage-class-default",
+			check: &StorageClassCheck{},
+			context: &Context{Clientset: fake.NewSimpleClientset(&storagev1.StorageClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "standard",
+					Annotations: map[string]string{defaultStorageClassAnnotation: "true"},
+				},
+			})},
+			expected: Pass,
+		},
+		{
+			name:     "no-default-storage-class",
+			check:    &StorageClassCheck{},
+			context:  &Context{Clientset: fake.NewSimpleClientset(&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "standard"}})},
+			expected: Warn,
+		},
+		{
+			name:     "resources-minimal",
+			check:    &NodeResourcesCheck{},
+			context:  &Context{Clientset: nodeClientset("4", "8Gi"), Profile: profile.Minimal},
+			expected: Pass,
+		},
+		{
+			name:     "resources-default",
+			check:    &NodeResourcesCheck{},
+			context:  &Context{Clientset: nodeClientset("4", "8Gi"), Profile: profile.Default},
+			expected: Fail,
+		},
+		{
+			name:     "istio-missing",
+			check:    &IstioCheck{},
+			context:  &Context{Clientset: fake.NewSimpleClientset()},
+			expected: Fail,
+		},
+		{
+			name:  "istio-running",
+			check: &IstioCheck{},
+			context: &Context{Clientset: fake.NewSimpleClientset(
+				&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: istioNamespace}},
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: istioDeployment, Namespace: istioNamespace},
+					Status: appsv1.DeploymentStatus{
+						Conditions: []appsv1.DeploymentCondition{
+							{Type: appsv1.DeploymentAvailable, Status: v1.ConditionTrue},
+						},
+					},
+				},
+			)},
+			expected: Pass,
+		},
+	}
+	for _, single := range data {
+		t.Run(single.name, func(single struct {
+			name     string
+			check    Check
+			context  *Context
+			expected Result
+		}) func(t *testing.T) {
+			return func(t *testing.T) {
+				result := single.check.Run(context.TODO(), single.context)
+				if result.Result != single.expected {
+					t.Fatalf("expected result: %s got result: %s (%s)", single.expected, result.Result, result.Message)
+				}
+			}
+		}(single))
+	}
+}
+
+func nodeClientset(cpu string, memory string) kubernetes.Interface {
+	return fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node"},
+		Status: v1.NodeStatus{
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(cpu),
+				v1.ResourceMemory: resource.MustParse(memory),
+			},
+		},
+	})
+}
@@ -0,0 +1,142 @@
+/**
+ * Copyright © 2014-2021 The SiteWhere Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package preflight implements the checks run before installing SiteWhere
+// or creating an instance
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sitewhere/swctl/pkg/install/profile"
+)
+
+// Result is the result of a pre-flight check
+type Result string
+
+const (
+	// Pass the check passed
+	Pass Result = "Pass"
+	// Warn the check passed with warnings
+	Warn Result = "Warn"
+	// Fail the check failed
+	Fail Result = "Fail"
+)
+
+// Context holds the values used by the pre-flight checks
+type Context struct {
+	// Clientset is the kubernetes clientset
+	Clientset kubernetes.Interface
+	// StorageClass is the storage class requested for the infrastructure
+	StorageClass string
+	// Profile is the profile to install
+	Profile profile.SiteWhereProfile
+}
+
+// Check is a pre-flight check
+type Check interface {
+	// Name of the check
+	Name() string
+	// Run executes the check
+	Run(ctx context.Context, c *Context) CheckResult
+}
+
+// CheckResult is the result of running a pre-flight check
+type CheckResult struct {
+	// Name of the check
+	Name string `json:"name"`
+	// Result of the check
+	Result Result `json:"result"`
+	// Message describing the result
+	Message string `json:"message,omitempty"`
+}
+
+// Report is the result of running a set of pre-flight checks
+type Report struct {
+	// Results of the checks
+	Results []CheckResult `json:"results"`
+}
+
+// Failed returns true if any check failed
+func (r *Report) Failed() bool {
+	for _, result := range r.Results {
+		if result.Result == Fail {
+			return true
+		}
+	}
+	return false
+}
+
+// Failures returns the results of the failed checks
+func (r *Report) Failures() []CheckResult {
+	var failures []CheckResult
+	for _, result := range r.Results {
+		if result.Result == Fail {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+// Err returns an error describing the failed checks, or nil if no check failed
+func (r *Report) Err() error {
+	failures := r.Failures()
+	if len(failures) == 0 {
+		return nil
+	}
+	var messages []string
+	for _, failure := range failures {
+		messages = append(messages, fmt.Sprintf("%s: %s", failure.Name, failure.Message))
+	}
+	return errors.Errorf("pre-flight checks failed:\n  %s", strings.Join(messages, "\n  "))
+}
+
+// Run executes the checks in order and returns the report
+func Run(ctx context.Context, checks []Check, c *Context) *Report {
+	report := &Report{}
+	for _, check := range checks {
+		result := check.Run(ctx, c)
+		result.Name = check.Name()
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+// InstallChecks returns the checks run before installing SiteWhere
+func InstallChecks() []Check {
+	return []Check{
+		&KubernetesVersionCheck{Min: MinKubernetesVersion, Max: MaxKubernetesVersion},
+		&StorageClassCheck{},
+		&NodeResourcesCheck{},
+		&ClusterPermissionsCheck{},
+		&IstioCheck{},
+	}
+}
+
+// InstanceChecks returns the checks run before creating a SiteWhere instance
+func InstanceChecks() []Check {
+	return []Check{
+		&KubernetesVersionCheck{Min: MinKubernetesVersion, Max: MaxKubernetesVersion},
+		&NodeResourcesCheck{},
+		&IstioCheck{},
+	}
+}
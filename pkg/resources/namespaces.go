@@ -32,7 +32,8 @@ var (
 	decUnstructured          = yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme) // Decoding Unstructed
 )
 
-// SitewhereSystemNamespace returns the namespace for SiteWhere
+// SitewhereSystemNamespace returns the default namespace for SiteWhere.
+// The namespace in use is configured by swctl settings.
 func SitewhereSystemNamespace() string {
 	return sitewhereSystemNamespace
 }
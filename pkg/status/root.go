@@ -30,14 +30,24 @@ const (
 	Uninstalled = "Uninstalled"
 	// Unknown We cannot know if the item is installed or not.
 	Unknown = "Unknown"
+	// NotReady The item is installed but it is not ready.
+	NotReady Status = "NotReady"
+	// Skipped The item was not selected for install.
+	Skipped Status = "Skipped"
+	// RolledBack The item was reverted to its previous state.
+	RolledBack Status = "RolledBack"
 )
 
 // SiteWhereStatus represents that status of a installation resource
 type SiteWhereStatus struct {
 	// Name of the Custom Resource Definition
 	Name string `json:"name,omitempty"`
+	// Kind of the resource
+	Kind string `json:"kind,omitempty"`
 	// Install Status
 	Status Status `json:"status,omitempty"`
+	// Detail of the status
+	Detail string `json:"detail,omitempty"`
 	// Object Meta
 	ObjectMeta *metav1.ObjectMeta `json:"object_meta,omitempty"`
 }
@@ -23,4 +23,7 @@ type CreateSiteWhereTenant struct {
 
 	// Name of the tenant
 	TenantName string `json:"tenant_name"`
+
+	// Bootstrapped is true if the tenant engines were waited for and bootstrapped
+	Bootstrapped bool `json:"bootstrapped,omitempty"`
 }